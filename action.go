@@ -0,0 +1,258 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Action is an ir.actions dictionary returned by a button or wizard, such
+// as a window to open or a report to print.
+type Action struct {
+	// Type is the action model, e.g. "ir.actions.act_window".
+	Type string
+	Name string
+	// Context is the context of the action, when the server sent it as a
+	// dictionary rather than a Python expression.
+	Context map[string]any
+	// Raw holds the full action dictionary.
+	Raw map[string]any
+}
+
+// DecodeAction decodes the result of a button method. Buttons that do not
+// lead anywhere return true, false or None, for which DecodeAction returns
+// nil.
+func DecodeAction(result any) (*Action, error) {
+	switch v := result.(type) {
+	case nil, bool:
+		return nil, nil
+	case json.RawMessage:
+		var decoded any
+		if err := json.Unmarshal(v, &decoded); err != nil {
+			return nil, err
+		}
+		return DecodeAction(decoded)
+	case map[string]any:
+		a := &Action{Raw: v}
+		a.Type, _ = v["type"].(string)
+		a.Name, _ = v["name"].(string)
+		a.Context, _ = v["context"].(map[string]any)
+		if a.Type == "" {
+			return nil, fmt.Errorf("action without type: %v", v)
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("unexpected action result %T", result)
+}
+
+// Action types.
+const (
+	ActionWindow      = "ir.actions.act_window"
+	ActionWindowClose = "ir.actions.act_window_close"
+	ActionReport      = "ir.actions.report"
+	ActionURL         = "ir.actions.act_url"
+	ActionClient      = "ir.actions.client"
+)
+
+// ErrInteractionRequired is wrapped by FollowAction errors for actions
+// that need a user: dialogs, URLs to open, client side screens.
+var ErrInteractionRequired = errors.New("action requires user interaction")
+
+// InteractionError is returned by FollowAction for actions it cannot
+// complete on its own.
+type InteractionError struct {
+	Action *Action
+	Reason string
+}
+
+func (e *InteractionError) Error() string {
+	return fmt.Sprintf("%s %q: %s", e.Action.Type, e.Action.Name, e.Reason)
+}
+
+func (e *InteractionError) Unwrap() error { return ErrInteractionRequired }
+
+// WindowAction opens a model in a view, or a wizard when Target is "new".
+type WindowAction struct {
+	Name     string
+	ResModel string
+	// ResID is the record to open, zero for a new record or a list.
+	ResID    int64
+	ViewMode string
+	// Target is "new" for dialogs (wizards), "current" or "main" otherwise.
+	Target  string
+	Domain  Domain
+	Context map[string]any
+}
+
+// ReportAction prints a report for a set of records.
+type ReportAction struct {
+	Name       string
+	ReportName string
+	// ReportType is "qweb-pdf", "qweb-html" or "qweb-text".
+	ReportType string
+	IDs        []int64
+	// Data holds the values passed by reports printed from wizards, which
+	// may print no record at all.
+	Data    map[string]any
+	Context map[string]any
+}
+
+// URLAction opens a URL.
+type URLAction struct {
+	URL    string
+	Target string
+}
+
+// ClientAction triggers a screen or effect of the web client, identified
+// by Tag (e.g. "reload" or "display_notification").
+type ClientAction struct {
+	Tag    string
+	Params map[string]any
+}
+
+// Window returns the action as a WindowAction.
+func (a *Action) Window() (*WindowAction, bool) {
+	if a == nil || a.Type != ActionWindow {
+		return nil, false
+	}
+	w := &WindowAction{
+		Name:     a.Name,
+		ResModel: stringValue(a.Raw["res_model"]),
+		ViewMode: stringValue(a.Raw["view_mode"]),
+		Target:   stringValue(a.Raw["target"]),
+		Context:  a.Context,
+	}
+	if id, ok := a.Raw["res_id"].(float64); ok {
+		w.ResID = int64(id)
+	}
+	if domain, ok := a.Raw["domain"].([]any); ok {
+		w.Domain = Domain(domain)
+	} else if s, ok := a.Raw["domain"].(string); ok {
+		w.Domain, _ = ParseDomain(s)
+	}
+	return w, true
+}
+
+// IsWizard reports whether the action opens a dialog, usually a wizard
+// the user should fill and confirm.
+func (a *Action) IsWizard() bool {
+	w, ok := a.Window()
+	return ok && w.Target == "new"
+}
+
+// Report returns the action as a ReportAction. The records to print are
+// taken from the active_ids of the action context.
+func (a *Action) Report() (*ReportAction, bool) {
+	if a == nil || a.Type != ActionReport {
+		return nil, false
+	}
+	data, _ := a.Raw["data"].(map[string]any)
+	return &ReportAction{
+		Name:       a.Name,
+		ReportName: stringValue(a.Raw["report_name"]),
+		ReportType: stringValue(a.Raw["report_type"]),
+		IDs:        int64List(a.Context["active_ids"]),
+		Data:       data,
+		Context:    a.Context,
+	}, true
+}
+
+// URL returns the action as a URLAction.
+func (a *Action) URL() (*URLAction, bool) {
+	if a == nil || a.Type != ActionURL {
+		return nil, false
+	}
+	return &URLAction{URL: stringValue(a.Raw["url"]), Target: stringValue(a.Raw["target"])}, true
+}
+
+// Client returns the action as a ClientAction.
+func (a *Action) Client() (*ClientAction, bool) {
+	if a == nil || a.Type != ActionClient {
+		return nil, false
+	}
+	params, _ := a.Raw["params"].(map[string]any)
+	return &ClientAction{Tag: stringValue(a.Raw["tag"]), Params: params}, true
+}
+
+// CallButton calls a button method on records and decodes the action it
+// returns, nil when it returns none. Unlike CallMethod, the result is not
+// flattened.
+func (c *RpcClient) CallButton(ctx context.Context, model, method string, ids []int64, opts Options) (*Action, error) {
+	var res any
+	if err := c.executeKw(ctx, model, method, []any{ids}, opts.Kwargs(), &res); err != nil {
+		return nil, err
+	}
+	return DecodeAction(res)
+}
+
+// RunWizardAction continues a wizard opened by a button: it calls button
+// on the wizard record the action points to, or creates one with the
+// action context and values as RunWizard does.
+func (c *RpcClient) RunWizardAction(ctx context.Context, w *WindowAction, button string, values map[string]any) (*Action, error) {
+	if w.ResID == 0 {
+		return c.RunWizard(ctx, w.ResModel, button, WizardOptions{Values: values, Context: w.Context})
+	}
+	opts := Options{Context: w.Context}
+	if len(values) > 0 {
+		if err := c.executeKw(ctx, w.ResModel, "write", []any{[]int64{w.ResID}, values}, opts.Kwargs(), nil); err != nil {
+			return nil, err
+		}
+	}
+	return c.CallButton(ctx, w.ResModel, button, []int64{w.ResID}, opts)
+}
+
+// DownloadReportAction renders the report of a report action to w, with
+// the action data and context.
+func (c *RpcClient) DownloadReportAction(ctx context.Context, r *ReportAction, w io.Writer) (int64, error) {
+	if len(r.IDs) == 0 && len(r.Data) == 0 {
+		return 0, fmt.Errorf("report %s: no records to print", r.ReportName)
+	}
+	format := ReportPDF
+	switch r.ReportType {
+	case "qweb-html":
+		format = ReportHTML
+	case "qweb-text":
+		format = ReportText
+	}
+	return c.withCallContext(r.Context).renderReport(ctx, r.ReportName, r.IDs, r.Data, format, w)
+}
+
+// FollowAction completes what the web client would do with an action
+// without asking the user: reports are downloaded to w, and actions that
+// only close a dialog, reload or open a record are done. Dialogs, URLs and
+// other client actions return an *InteractionError.
+func (c *RpcClient) FollowAction(ctx context.Context, a *Action, w io.Writer) error {
+	if a == nil {
+		return nil
+	}
+	switch a.Type {
+	case ActionWindowClose:
+		return nil
+	case ActionWindow:
+		if a.IsWizard() {
+			win, _ := a.Window()
+			return &InteractionError{Action: a, Reason: "wizard " + win.ResModel + " must be confirmed, see RunWizardAction"}
+		}
+		return nil
+	case ActionReport:
+		r, _ := a.Report()
+		if w == nil {
+			return &InteractionError{Action: a, Reason: "report " + r.ReportName + " needs a writer"}
+		}
+		_, err := c.DownloadReportAction(ctx, r, w)
+		return err
+	case ActionURL:
+		u, _ := a.URL()
+		return &InteractionError{Action: a, Reason: "open " + u.URL}
+	case ActionClient:
+		ca, _ := a.Client()
+		switch ca.Tag {
+		case "reload", "soft_reload", "display_notification":
+			return nil
+		}
+		return &InteractionError{Action: a, Reason: "client action " + ca.Tag}
+	}
+	return &InteractionError{Action: a, Reason: "unsupported action type"}
+}
@@ -0,0 +1,157 @@
+package odoorpc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestCallButtonDecodesWizardAction(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "button_validate":
+			return map[string]any{
+				"type": "ir.actions.act_window", "name": "Create Backorder?",
+				"res_model": "stock.backorder.confirmation", "res_id": 12,
+				"view_mode": "form", "target": "new",
+				"context": map[string]any{"button_validate_picking_ids": []any{float64(7)}},
+			}, nil
+		case "process":
+			return true, nil
+		}
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	action, err := c.CallButton(ctx, "stock.picking", "button_validate", []int64{7}, odoorpc.Options{})
+	if err != nil {
+		t.Fatalf("CallButton: %v", err)
+	}
+	if !action.IsWizard() {
+		t.Fatalf("expected a wizard, got %+v", action)
+	}
+	win, _ := action.Window()
+	if win.ResModel != "stock.backorder.confirmation" || win.ResID != 12 || win.ViewMode != "form" {
+		t.Fatalf("unexpected window %+v", win)
+	}
+	if err := c.FollowAction(ctx, action, nil); !errors.Is(err, odoorpc.ErrInteractionRequired) {
+		t.Fatalf("expected ErrInteractionRequired, got %v", err)
+	}
+
+	next, err := c.RunWizardAction(ctx, win, "process", nil)
+	if err != nil || next != nil {
+		t.Fatalf("RunWizardAction: %v %v", next, err)
+	}
+	last := srv.Calls[len(srv.Calls)-1]
+	if last.Model != "stock.backorder.confirmation" || !reflect.DeepEqual(last.Args, []any{[]any{float64(12)}}) {
+		t.Errorf("unexpected wizard call %+v", last)
+	}
+	if got := last.Kwargs["context"]; !reflect.DeepEqual(got, win.Context) {
+		t.Errorf("wizard context = %v", got)
+	}
+}
+
+func TestFollowActionDownloadsReport(t *testing.T) {
+	srv := reportServer(t)
+	srv.Mux.HandleFunc("/report/pdf/account.report_invoice/7,8", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.Authenticate(ctx, "admin", "admin", "odoo"); err != nil {
+		t.Fatal(err)
+	}
+	action, err := odoorpc.DecodeAction(map[string]any{
+		"type": "ir.actions.report", "name": "Invoices",
+		"report_name": "account.report_invoice", "report_type": "qweb-pdf",
+		"context": map[string]any{"active_ids": []any{float64(7), float64(8)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, ok := action.Report()
+	if !ok || !reflect.DeepEqual(report.IDs, []int64{7, 8}) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	var buf bytes.Buffer
+	if err := c.FollowAction(ctx, action, &buf); err != nil {
+		t.Fatalf("FollowAction: %v", err)
+	}
+	if buf.String() != "%PDF-1.7" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestFollowActionClientAndURL(t *testing.T) {
+	c := odoorpc.New("http://odoo.invalid", nil)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		raw         map[string]any
+		interaction bool
+	}{
+		{map[string]any{"type": "ir.actions.act_window_close"}, false},
+		{map[string]any{"type": "ir.actions.client", "tag": "reload"}, false},
+		{map[string]any{"type": "ir.actions.client", "tag": "display_notification", "params": map[string]any{"message": "Done"}}, false},
+		{map[string]any{"type": "ir.actions.act_window", "res_model": "sale.order", "res_id": float64(3), "target": "current"}, false},
+		{map[string]any{"type": "ir.actions.client", "tag": "account_reconcile"}, true},
+		{map[string]any{"type": "ir.actions.act_url", "url": "https://example.com/pay", "target": "new"}, true},
+	} {
+		action, err := odoorpc.DecodeAction(tc.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = c.FollowAction(ctx, action, nil)
+		var ierr *odoorpc.InteractionError
+		if got := errors.As(err, &ierr); got != tc.interaction {
+			t.Errorf("%v: FollowAction = %v", tc.raw, err)
+		}
+	}
+	action, _ := odoorpc.DecodeAction(map[string]any{"type": "ir.actions.act_url", "url": "https://example.com/pay"})
+	if u, ok := action.URL(); !ok || u.URL != "https://example.com/pay" {
+		t.Errorf("unexpected url %+v", u)
+	}
+	if _, ok := action.Window(); ok {
+		t.Errorf("url action decoded as window")
+	}
+}
+
+func TestDownloadReportActionForwardsData(t *testing.T) {
+	srv := reportServer(t)
+	srv.Mux.HandleFunc("/report/pdf/account.report_agedpartnerbalance", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("options"); got != `{"date_from":"2024-01-01","form":{"period_length":30}}` {
+			t.Errorf("options = %s", got)
+		}
+		if got := r.URL.Query().Get("context"); got != `{"active_model":"account.aged.trial.balance"}` {
+			t.Errorf("context = %s", got)
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.Authenticate(ctx, "admin", "admin", "odoo"); err != nil {
+		t.Fatal(err)
+	}
+	action, err := odoorpc.DecodeAction(map[string]any{
+		"type": "ir.actions.report", "report_name": "account.report_agedpartnerbalance", "report_type": "qweb-pdf",
+		"data":    map[string]any{"date_from": "2024-01-01", "form": map[string]any{"period_length": float64(30)}},
+		"context": map[string]any{"active_model": "account.aged.trial.balance"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, _ := action.Report()
+	var buf bytes.Buffer
+	if _, err := c.DownloadReportAction(ctx, report, &buf); err != nil {
+		t.Fatalf("DownloadReportAction: %v", err)
+	}
+	if buf.String() != "%PDF-1.7" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,219 @@
+package odoorpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Guadalsistema/odoorpc/jsonrpc"
+)
+
+// ErrChecksumMismatch is returned when the SHA-1 of transferred attachment
+// data differs from the checksum stored by Odoo.
+var ErrChecksumMismatch = errors.New("attachment checksum mismatch")
+
+// Attachment describes an ir.attachment record.
+type Attachment struct {
+	ID       int64
+	Name     string
+	Mimetype string
+	// FileSize is the size of the data in bytes.
+	FileSize int64
+	// Checksum is the hex SHA-1 of the data, as stored by Odoo.
+	Checksum string
+	// ResModel and ResID identify the record the attachment belongs to.
+	ResModel string
+	ResID    int64
+	// Type is "binary" for stored files and "url" for links.
+	Type string
+	URL  string
+}
+
+var attachmentFields = []string{"name", "mimetype", "file_size", "checksum", "res_model", "res_id", "type", "url"}
+
+func attachmentFromRecord(rec map[string]any) Attachment {
+	a := Attachment{
+		Name:     stringValue(rec["name"]),
+		Mimetype: stringValue(rec["mimetype"]),
+		Checksum: stringValue(rec["checksum"]),
+		ResModel: stringValue(rec["res_model"]),
+		Type:     stringValue(rec["type"]),
+		URL:      stringValue(rec["url"]),
+	}
+	if id, ok := rec["id"].(float64); ok {
+		a.ID = int64(id)
+	}
+	if size, ok := rec["file_size"].(float64); ok {
+		a.FileSize = int64(size)
+	}
+	if resID, ok := rec["res_id"].(float64); ok {
+		a.ResID = int64(resID)
+	}
+	return a
+}
+
+// AttachmentOptions describes an uploaded attachment.
+type AttachmentOptions struct {
+	// ResModel and ResID link the attachment to a record.
+	ResModel string
+	ResID    int64
+	// Mimetype is detected from the file name and content when empty.
+	Mimetype string
+	// Size is the number of bytes r yields; it is detected for files and
+	// in-memory readers, and a negative value streams the upload chunked.
+	Size int64
+	// Context is passed to the create call.
+	Context map[string]any
+}
+
+// Attachment returns the metadata of an attachment.
+func (c *RpcClient) Attachment(ctx context.Context, id int64) (Attachment, error) {
+	recs, err := c.Read(ctx, "ir.attachment", []int64{id}, Options{Fields: attachmentFields})
+	if err != nil {
+		return Attachment{}, err
+	}
+	if len(recs) == 0 {
+		return Attachment{}, fmt.Errorf("attachment %d not found", id)
+	}
+	return attachmentFromRecord(recs[0]), nil
+}
+
+// Attachments lists the attachments of a record, newest first.
+func (c *RpcClient) Attachments(ctx context.Context, model string, resID int64) ([]Attachment, error) {
+	domain := NewDomain().Equals("res_model", model).Equals("res_id", resID)
+	recs, err := c.SearchRead(ctx, "ir.attachment", domain, Options{Fields: attachmentFields, Order: "id desc"})
+	if err != nil {
+		return nil, err
+	}
+	atts := make([]Attachment, len(recs))
+	for i, rec := range recs {
+		atts[i] = attachmentFromRecord(rec)
+	}
+	return atts, nil
+}
+
+// UploadAttachment creates an attachment named name with the content of r.
+// The data is base64 encoded while the request is sent, so files of any
+// size are uploaded without being held in memory. The checksum computed
+// by Odoo is compared with the one of the sent data.
+func (c *RpcClient) UploadAttachment(ctx context.Context, name string, r io.Reader, opts AttachmentOptions) (Attachment, error) {
+	// Measure r before bufio reads ahead of it to sniff the type.
+	size := opts.Size
+	if size == 0 {
+		size = readerSize(r)
+	}
+	br := bufio.NewReader(r)
+	if opts.Mimetype == "" {
+		opts.Mimetype = detectMimetype(name, br)
+	}
+	sum := sha1.New()
+	values := map[string]any{
+		"name":     name,
+		"datas":    jsonrpc.NewBase64Value(io.TeeReader(br, sum), size),
+		"mimetype": opts.Mimetype,
+	}
+	if opts.ResModel != "" {
+		values["res_model"] = opts.ResModel
+		values["res_id"] = opts.ResID
+	}
+	var id int64
+	kwargs := Options{Context: opts.Context}.Kwargs()
+	if err := c.executeKw(ctx, "ir.attachment", "create", []any{values}, kwargs, &id); err != nil {
+		return Attachment{}, err
+	}
+	att, err := c.Attachment(ctx, id)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if err := verifyChecksum(att, sum); err != nil {
+		return att, err
+	}
+	return att, nil
+}
+
+// DownloadAttachment streams the content of an attachment to w, decoding
+// the base64 data as it is received, and checks it against the stored
+// checksum. It returns the number of bytes written.
+func (c *RpcClient) DownloadAttachment(ctx context.Context, id int64, w io.Writer) (int64, error) {
+	att, err := c.Attachment(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if att.Type == "url" {
+		return 0, fmt.Errorf("attachment %d is a link to %s", id, att.URL)
+	}
+	path, params, err := c.kwRequest(ctx, "ir.attachment", "read", []any{[]int64{id}}, map[string]any{"fields": []string{"datas"}})
+	if err != nil {
+		return 0, err
+	}
+	sum := sha1.New()
+	n, err := c.rpc.CallBase64(ctx, path, "call", params, "datas", io.MultiWriter(w, sum))
+	if errors.Is(err, jsonrpc.ErrNoValue) {
+		return 0, nil
+	}
+	if err != nil {
+		return n, classifyError("ir.attachment", err)
+	}
+	return n, verifyChecksum(att, sum)
+}
+
+func verifyChecksum(att Attachment, sum hash.Hash) error {
+	if att.Checksum == "" {
+		return nil
+	}
+	if got := hex.EncodeToString(sum.Sum(nil)); got != att.Checksum {
+		return fmt.Errorf("%w: attachment %d is %s, got %s", ErrChecksumMismatch, att.ID, att.Checksum, got)
+	}
+	return nil
+}
+
+// detectMimetype guesses the mimetype from the file extension, then from
+// the first bytes of the content.
+func detectMimetype(name string, br *bufio.Reader) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); t != "" {
+		if mt, _, err := mime.ParseMediaType(t); err == nil {
+			return mt
+		}
+	}
+	head, _ := br.Peek(512)
+	if len(head) == 0 {
+		return "application/octet-stream"
+	}
+	mt, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mt
+}
+
+// readerSize returns the number of bytes left in r when it can be known
+// without reading it, or -1.
+func readerSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case *bytes.Reader:
+		return int64(r.Len())
+	case *bytes.Buffer:
+		return int64(r.Len())
+	case *strings.Reader:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		pos, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - pos
+	}
+	return -1
+}
@@ -0,0 +1,99 @@
+package odoorpc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func attachmentServer(t *testing.T, content []byte, checksum string) *fakeOdoo {
+	var stored string
+	return newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "login":
+			return 2, nil
+		case "create":
+			values := call.Args[0].(map[string]any)
+			stored, _ = values["datas"].(string)
+			if values["res_model"] != "res.partner" || values["mimetype"] != "application/pdf" {
+				t.Errorf("unexpected values %v", values)
+			}
+			return 12, nil
+		case "read":
+			if fields := call.Kwargs["fields"].([]any); len(fields) == 1 && fields[0] == "datas" {
+				return []any{map[string]any{"id": 12, "datas": base64.StdEncoding.EncodeToString(content)}}, nil
+			}
+			if stored != "" {
+				if data, _ := base64.StdEncoding.DecodeString(stored); !bytes.Equal(data, content) {
+					t.Errorf("server received %q", data)
+				}
+			}
+			return []any{map[string]any{
+				"id": 12, "name": "scan.pdf", "mimetype": "application/pdf", "file_size": len(content),
+				"checksum": checksum, "res_model": "res.partner", "res_id": 7, "type": "binary", "url": false,
+			}}, nil
+		}
+		return nil, nil
+	})
+}
+
+func sha1Hex(b []byte) string {
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadAttachmentStreamsData(t *testing.T) {
+	content := bytes.Repeat([]byte("%PDF-1.7 scan data "), 10000)
+	srv := attachmentServer(t, content, sha1Hex(content))
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.Authenticate(ctx, "admin", "admin", "odoo"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		r    io.Reader
+	}{
+		{"scan.pdf", bytes.NewReader(content)},
+		{"scan.pdf", io.MultiReader(bytes.NewReader(content))},
+		// Sniffing the type reads ahead of a sized reader.
+		{"scan", bytes.NewReader(content)},
+	} {
+		att, err := c.UploadAttachment(ctx, tc.name, tc.r, odoorpc.AttachmentOptions{ResModel: "res.partner", ResID: 7})
+		if err != nil {
+			t.Fatalf("UploadAttachment(%s): %v", tc.name, err)
+		}
+		if att.ID != 12 || att.ResID != 7 || att.FileSize != int64(len(content)) {
+			t.Errorf("unexpected attachment %+v", att)
+		}
+	}
+}
+
+func TestDownloadAttachmentVerifiesChecksum(t *testing.T) {
+	content := []byte(strings.Repeat("payload/", 5000))
+	ctx := context.Background()
+
+	srv := attachmentServer(t, content, sha1Hex(content))
+	c := odoorpc.New(srv.URL, nil)
+	c.Authenticate(ctx, "admin", "admin", "odoo")
+	var buf bytes.Buffer
+	n, err := c.DownloadAttachment(ctx, 12, &buf)
+	if err != nil || n != int64(len(content)) || !bytes.Equal(buf.Bytes(), content) {
+		t.Fatalf("DownloadAttachment = %d, %v", n, err)
+	}
+
+	bad := attachmentServer(t, content, sha1Hex([]byte("other")))
+	c = odoorpc.New(bad.URL, nil)
+	c.Authenticate(ctx, "admin", "admin", "odoo")
+	if _, err := c.DownloadAttachment(ctx, 12, io.Discard); !errors.Is(err, odoorpc.ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
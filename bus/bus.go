@@ -0,0 +1,294 @@
+// Package bus subscribes to Odoo's real-time notifications.
+//
+// Odoo 16 and later push notifications over a websocket at /websocket;
+// older versions answer long polling requests at /longpolling/poll. Both
+// rely on the web session cookie, so the client must be logged in with
+// SessionAuthenticate:
+//
+//	client := odoorpc.New(url, nil)
+//	if _, err := client.SessionAuthenticate(ctx, user, password, db, nil); err != nil {
+//		return err
+//	}
+//	sub := bus.Subscribe(ctx, client, []string{"stock.picking"}, bus.Options{})
+//	for msg := range sub.C {
+//		if msg.Type == "stock.picking/updated" {
+//			...
+//		}
+//	}
+//
+// The subscriber reconnects with exponential backoff and resumes from the
+// id of the last delivered notification, so nothing is missed while the
+// server keeps it (Odoo vacuums notifications after a few minutes).
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/jsonrpc"
+)
+
+// Default reconnection delays.
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+// Transport selects how notifications are received.
+type Transport int
+
+const (
+	// Auto uses the websocket on Odoo 16 and later, long polling before.
+	Auto Transport = iota
+	WebSocket
+	LongPolling
+)
+
+// Message is a bus notification.
+type Message struct {
+	// ID increases with each notification of the database.
+	ID int64
+	// Type identifies the kind of notification, e.g. "mail.record/insert".
+	// It is empty for servers older than 15, whose messages have no type.
+	Type string
+	// Payload is the notification content, or the whole message on servers
+	// older than 15.
+	Payload json.RawMessage
+	// Channel is the target channel; only long polling servers report it.
+	Channel json.RawMessage
+}
+
+// Decode unmarshals the payload into v.
+func (m Message) Decode(v any) error {
+	return json.Unmarshal(m.Payload, v)
+}
+
+// Options controls a Subscriber.
+type Options struct {
+	Transport Transport
+	// Last resumes after the notification with this id. With zero, Odoo
+	// starts with the notifications of the last minute.
+	Last int64
+	// MinBackoff and MaxBackoff bound the delay between reconnections.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Buffer is the capacity of the C channel.
+	Buffer int
+	// OnError is called with connection errors before reconnecting.
+	OnError func(error)
+}
+
+// Subscriber receives the notifications of a set of channels.
+type Subscriber struct {
+	// C delivers notifications in id order. It is closed when the context
+	// given to Subscribe is done.
+	C <-chan Message
+
+	c      chan Message
+	client *odoorpc.RpcClient
+	opts   Options
+	last   atomic.Int64
+
+	mu       sync.Mutex
+	channels []string
+	ws       *jsonrpc.WebSocket
+}
+
+// Subscribe starts receiving the notifications of channels, in addition to
+// those Odoo sends to the logged in user, until ctx is done.
+func Subscribe(ctx context.Context, client *odoorpc.RpcClient, channels []string, opts Options) *Subscriber {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(DefaultMaxBackoff, opts.MinBackoff)
+	}
+	c := make(chan Message, opts.Buffer)
+	s := &Subscriber{C: c, c: c, client: client, opts: opts, channels: channels}
+	s.last.Store(opts.Last)
+	go s.run(ctx)
+	return s
+}
+
+// Last returns the id of the last delivered notification, to resume a
+// later subscription with Options.Last.
+func (s *Subscriber) Last() int64 {
+	return s.last.Load()
+}
+
+// AddChannels subscribes to more channels. With the websocket transport
+// the subscription is renewed immediately; long polling picks them up on
+// the next request.
+func (s *Subscriber) AddChannels(channels ...string) error {
+	s.mu.Lock()
+	s.channels = append(s.channels, channels...)
+	ws := s.ws
+	s.mu.Unlock()
+	if ws == nil {
+		return nil
+	}
+	return s.subscribe(ws)
+}
+
+func (s *Subscriber) currentChannels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.channels...)
+}
+
+func (s *Subscriber) run(ctx context.Context) {
+	defer close(s.c)
+	backoff := s.opts.MinBackoff
+	for {
+		progressed, err := s.connect(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && s.opts.OnError != nil {
+			s.opts.OnError(err)
+		}
+		if progressed {
+			backoff = s.opts.MinBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, s.opts.MaxBackoff)
+	}
+}
+
+// connect runs one connection until it fails. progressed reports whether
+// the connection was established, to reset the backoff.
+func (s *Subscriber) connect(ctx context.Context) (progressed bool, err error) {
+	transport := s.opts.Transport
+	if transport == Auto {
+		v, err := s.client.Version(ctx)
+		if err != nil {
+			return false, err
+		}
+		transport = LongPolling
+		if v.ServerVersionInfo.Major >= 16 {
+			transport = WebSocket
+		}
+		s.opts.Transport = transport
+	}
+	if transport == WebSocket {
+		return s.websocket(ctx)
+	}
+	return s.longpoll(ctx)
+}
+
+// notification is the wire format of both transports.
+type notification struct {
+	ID      int64           `json:"id"`
+	Channel json.RawMessage `json:"channel"`
+	Message json.RawMessage `json:"message"`
+}
+
+func (n notification) message() Message {
+	msg := Message{ID: n.ID, Channel: n.Channel, Payload: n.Message}
+	var typed struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if json.Unmarshal(n.Message, &typed) == nil && typed.Type != "" {
+		msg.Type = typed.Type
+		msg.Payload = typed.Payload
+	}
+	return msg
+}
+
+// deliver sends notifications newer than the last delivered one.
+func (s *Subscriber) deliver(ctx context.Context, notifs []notification) error {
+	for _, n := range notifs {
+		if n.ID <= s.last.Load() {
+			continue
+		}
+		select {
+		case s.c <- n.message():
+			s.last.Store(n.ID)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *Subscriber) subscribe(ws *jsonrpc.WebSocket) error {
+	data, err := json.Marshal(map[string]any{
+		"event_name": "subscribe",
+		"data":       map[string]any{"channels": s.currentChannels(), "last": s.last.Load()},
+	})
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(data)
+}
+
+func (s *Subscriber) websocket(ctx context.Context) (bool, error) {
+	ws, err := s.client.Transport().DialWebSocket(ctx, "/websocket", nil)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.ws = ws
+	s.mu.Unlock()
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		s.mu.Lock()
+		s.ws = nil
+		s.mu.Unlock()
+		ws.Close()
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	if err := s.subscribe(ws); err != nil {
+		return true, err
+	}
+	for {
+		data, err := ws.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		var notifs []notification
+		if err := json.Unmarshal(data, &notifs); err != nil {
+			return true, errors.New("bus: unexpected websocket message: " + string(data))
+		}
+		if err := s.deliver(ctx, notifs); err != nil {
+			return true, err
+		}
+	}
+}
+
+func (s *Subscriber) longpoll(ctx context.Context) (bool, error) {
+	progressed := false
+	for {
+		params := map[string]any{
+			"channels": s.currentChannels(),
+			"last":     s.last.Load(),
+			"options":  map[string]any{},
+		}
+		var notifs []notification
+		if err := s.client.Transport().CallPath(ctx, "/longpolling/poll", "call", params, &notifs); err != nil {
+			return progressed, err
+		}
+		progressed = true
+		if err := s.deliver(ctx, notifs); err != nil {
+			return progressed, err
+		}
+	}
+}
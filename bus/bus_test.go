@@ -0,0 +1,169 @@
+package bus_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/bus"
+)
+
+// acceptWebSocket performs the server side handshake.
+func acceptWebSocket(t *testing.T, w http.ResponseWriter, r *http.Request) (io.ReadWriteCloser, *bufio.Reader) {
+	t.Helper()
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		t.Fatalf("hijack: %v", err)
+	}
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	rw.Flush()
+	return conn, rw.Reader
+}
+
+// readClientFrame reads one masked text frame.
+func readClientFrame(t *testing.T, r *bufio.Reader) []byte {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	n := int(head[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	var mask [4]byte
+	io.ReadFull(r, mask[:])
+	payload := make([]byte, n)
+	io.ReadFull(r, payload)
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return payload
+}
+
+func writeServerFrame(w io.Writer, payload []byte) {
+	frame := []byte{0x81, byte(len(payload))}
+	w.Write(append(frame, payload...))
+}
+
+func TestWebSocketSubscriberResumesAfterReconnect(t *testing.T) {
+	type subscribe struct {
+		EventName string `json:"event_name"`
+		Data      struct {
+			Channels []string `json:"channels"`
+			Last     int64    `json:"last"`
+		} `json:"data"`
+	}
+	subs := make(chan subscribe, 2)
+	connections := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jsonrpc", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": map[string]any{
+			"server_version": "17.0", "server_version_info": []any{17, 0, 0, "final", 0, ""},
+		}})
+	})
+	var srv *httptest.Server
+	mux.HandleFunc("/websocket", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != srv.URL {
+			t.Errorf("unexpected Origin %q", r.Header.Get("Origin"))
+		}
+		conn, br := acceptWebSocket(t, w, r)
+		defer conn.Close()
+		var sub subscribe
+		json.Unmarshal(readClientFrame(t, br), &sub)
+		subs <- sub
+		connections++
+		if connections == 1 {
+			writeServerFrame(conn, []byte(`[{"id":41,"message":{"type":"stock.picking/updated","payload":{"id":7}}}]`))
+			return // drop the connection
+		}
+		writeServerFrame(conn, []byte(`[{"id":41,"message":{"type":"dup","payload":{}}},{"id":42,"message":{"type":"stock.picking/updated","payload":{"id":8}}}]`))
+		readClientFrame(t, br) // wait for the close frame
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := odoorpc.New(srv.URL, nil)
+	sub := bus.Subscribe(ctx, client, []string{"stock.picking"}, bus.Options{MinBackoff: time.Millisecond})
+
+	var ids []int64
+	for msg := range sub.C {
+		var payload struct{ ID int64 }
+		if err := msg.Decode(&payload); err != nil || msg.Type != "stock.picking/updated" {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+		ids = append(ids, payload.ID)
+		if len(ids) == 2 {
+			cancel()
+		}
+	}
+	if len(ids) != 2 || ids[0] != 7 || ids[1] != 8 {
+		t.Errorf("unexpected payloads %v", ids)
+	}
+	first, second := <-subs, <-subs
+	if first.EventName != "subscribe" || first.Data.Channels[0] != "stock.picking" || first.Data.Last != 0 {
+		t.Errorf("unexpected subscription %+v", first)
+	}
+	if second.Data.Last != 41 {
+		t.Errorf("reconnection should resume from 41, got %d", second.Data.Last)
+	}
+	if sub.Last() != 42 {
+		t.Errorf("Last = %d", sub.Last())
+	}
+}
+
+func TestLongPollingSubscriber(t *testing.T) {
+	polls := make(chan float64, 100)
+	n := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/longpolling/poll", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params struct {
+				Channels []string
+				Last     float64
+			}
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		select {
+		case polls <- req.Params.Last:
+		default:
+		}
+		n++
+		result := []any{}
+		if n == 1 {
+			result = append(result, map[string]any{"id": 5, "channel": []any{"db", "res.partner", 3}, "message": map[string]any{"type": "ping", "payload": "x"}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := bus.Subscribe(ctx, odoorpc.New(srv.URL, nil), []string{"custom"}, bus.Options{Transport: bus.LongPolling})
+	msg := <-sub.C
+	if msg.ID != 5 || msg.Type != "ping" || string(msg.Payload) != `"x"` || len(msg.Channel) == 0 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if first, second := <-polls, <-polls; first != 0 || second != 5 {
+		t.Errorf("second poll should resume from 5, got %v then %v", first, second)
+	}
+	cancel()
+	for range sub.C {
+	}
+}
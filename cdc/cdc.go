@@ -0,0 +1,324 @@
+// Package cdc provides a change feed over Odoo models by polling
+// write_date, for mirroring Odoo data into other systems.
+//
+//	feed := &cdc.Feed{
+//		Client: client,
+//		Model:  "res.partner",
+//		Fields: []string{"name", "email"},
+//		Store:  cdc.NewFileStore("/var/lib/mirror"),
+//	}
+//	err := feed.Run(ctx, func(ctx context.Context, b cdc.Batch) error {
+//		return warehouse.Apply(b.Events)
+//	})
+//
+// Delivery is at least once: the checkpoint is saved after the handler
+// returns successfully, so a batch may be delivered again after a crash and
+// handlers should apply events idempotently (upserts keyed by id).
+//
+// Odoo sets write_date to the start of the writing transaction, so a record
+// written by a transaction still running when the feed polls past its
+// timestamp is not seen until it is written again. Deletion detection by id
+// sets (DeleteByIDSet) catches such records the next time they change; for
+// models with long running writers, a periodic full resync is advisable.
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// DefaultBatchSize is the number of records fetched per request when
+// Feed.BatchSize is zero.
+const DefaultBatchSize = 500
+
+// DefaultInterval is the time between polls of Run when Feed.Interval is
+// zero.
+const DefaultInterval = time.Minute
+
+// odooDatetime is the layout of Odoo datetime values, always in UTC.
+const odooDatetime = "2006-01-02 15:04:05"
+
+// Op is the kind of change of an Event.
+type Op string
+
+const (
+	Created Op = "created"
+	Updated Op = "updated"
+	Deleted Op = "deleted"
+)
+
+// DeleteMode selects how a Feed detects deleted records.
+type DeleteMode int
+
+const (
+	// DeleteNone does not report deletions.
+	DeleteNone DeleteMode = iota
+	// DeleteByIDSet compares the ids matching the domain after every poll
+	// with those of the previous one. Records leaving the domain are
+	// reported as deleted too. The id set is kept in the checkpoint.
+	DeleteByIDSet
+	// DeleteArchived reports records archived (active = False) as deleted.
+	// The model must have an active field; unlinked records are not seen.
+	DeleteArchived
+)
+
+// Event is a change of one record.
+type Event struct {
+	Op Op
+	ID int64
+	// Record holds the requested fields; it is nil for deletions.
+	Record map[string]any
+}
+
+// Batch is a group of events delivered to a Handler, together with the
+// checkpoint that will be saved once the handler succeeds.
+type Batch struct {
+	Model      string
+	Events     []Event
+	Checkpoint Checkpoint
+}
+
+// Handler processes a batch. Returning an error stops the feed without
+// saving the checkpoint, so the batch is delivered again on the next run.
+type Handler func(ctx context.Context, b Batch) error
+
+// Checkpoint is the position of a Feed in the change stream of a model.
+type Checkpoint struct {
+	// WriteDate is the write_date of the last delivered record, in Odoo's
+	// "2006-01-02 15:04:05" UTC format. Empty before the first poll.
+	WriteDate string `json:"write_date,omitempty"`
+	// Seen lists the delivered ids whose write_date equals WriteDate.
+	// Odoo only returns datetimes to the second, so records sharing the
+	// last second are told apart by id.
+	Seen []int64 `json:"seen,omitempty"`
+	// Known is the id set of the last poll, kept for DeleteByIDSet.
+	Known []int64 `json:"known,omitempty"`
+}
+
+// Feed polls a model for changes. Its fields must not be modified once
+// polling started.
+type Feed struct {
+	Client odoorpc.Client
+	Model  string
+	// Domain restricts the records followed by the feed.
+	Domain odoorpc.Domain
+	// Fields are read for created and updated records. write_date,
+	// create_date and, for DeleteArchived, active are always read.
+	Fields []string
+	// BatchSize is the maximum number of records per batch.
+	BatchSize int
+	// Interval is the time Run waits between polls.
+	Interval time.Duration
+	Deletes  DeleteMode
+	// Store persists checkpoints. When nil, checkpoints live in memory
+	// and the feed starts from the beginning on each process start.
+	Store Store
+	// Key identifies the checkpoint in Store; it defaults to Model. Feeds
+	// over the same model with different domains need different keys.
+	Key string
+	// Context is passed to the ORM calls.
+	Context map[string]any
+}
+
+func (f *Feed) key() string {
+	if f.Key != "" {
+		return f.Key
+	}
+	return f.Model
+}
+
+func (f *Feed) batchSize() int {
+	if f.BatchSize > 0 {
+		return f.BatchSize
+	}
+	return DefaultBatchSize
+}
+
+func (f *Feed) store() Store {
+	if f.Store == nil {
+		f.Store = NewMemoryStore()
+	}
+	return f.Store
+}
+
+// Run polls the model until ctx is done or an error occurs, waiting
+// Interval between polls. It returns ctx.Err() when ctx is canceled.
+func (f *Feed) Run(ctx context.Context, handle Handler) error {
+	interval := f.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	for {
+		if _, err := f.Poll(ctx, handle); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Poll delivers every change since the saved checkpoint, in batches of at
+// most BatchSize records ordered by write_date, then the deletions. It
+// returns the number of events delivered.
+func (f *Feed) Poll(ctx context.Context, handle Handler) (int, error) {
+	store := f.store()
+	cp, _, err := store.Load(ctx, f.key())
+	if err != nil {
+		return 0, fmt.Errorf("cdc: load checkpoint: %w", err)
+	}
+	total := 0
+	for {
+		batch, next, err := f.changes(ctx, cp)
+		if err != nil {
+			return total, err
+		}
+		if len(batch.Events) == 0 {
+			break
+		}
+		if err := f.deliver(ctx, handle, batch); err != nil {
+			return total, err
+		}
+		total += len(batch.Events)
+		cp = batch.Checkpoint
+		if !next {
+			break
+		}
+	}
+	if f.Deletes == DeleteByIDSet {
+		batch, err := f.deletions(ctx, cp)
+		if err != nil {
+			return total, err
+		}
+		if len(batch.Events) > 0 || !slices.Equal(batch.Checkpoint.Known, cp.Known) {
+			if err := f.deliver(ctx, handle, batch); err != nil {
+				return total, err
+			}
+			total += len(batch.Events)
+		}
+	}
+	return total, nil
+}
+
+func (f *Feed) deliver(ctx context.Context, handle Handler, b Batch) error {
+	if len(b.Events) > 0 {
+		if err := handle(ctx, b); err != nil {
+			return err
+		}
+	}
+	if err := f.store().Save(ctx, f.key(), b.Checkpoint); err != nil {
+		return fmt.Errorf("cdc: save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (f *Feed) context() map[string]any {
+	if f.Deletes != DeleteArchived {
+		return f.Context
+	}
+	ctx := map[string]any{"active_test": false}
+	for k, v := range f.Context {
+		ctx[k] = v
+	}
+	return ctx
+}
+
+// changes reads the next batch of changed records after cp. next reports
+// whether more records may follow.
+func (f *Feed) changes(ctx context.Context, cp Checkpoint) (b Batch, next bool, err error) {
+	domain := slices.Clone(f.Domain)
+	if domain == nil {
+		domain = odoorpc.NewDomain()
+	}
+	if cp.WriteDate != "" {
+		domain = domain.GreaterThanOrEqual("write_date", cp.WriteDate)
+		if len(cp.Seen) > 0 {
+			after, err := time.Parse(odooDatetime, cp.WriteDate)
+			if err != nil {
+				return Batch{}, false, fmt.Errorf("cdc: invalid checkpoint: %w", err)
+			}
+			domain = append(domain, "|")
+			domain = domain.GreaterThanOrEqual("write_date", after.Add(time.Second).Format(odooDatetime))
+			domain = append(domain, []any{"id", "not in", cp.Seen})
+		}
+	}
+	fields := append(slices.Clone(f.Fields), "write_date", "create_date")
+	if f.Deletes == DeleteArchived {
+		fields = append(fields, "active")
+	}
+	size := f.batchSize()
+	recs, err := f.Client.SearchRead(ctx, f.Model, domain, odoorpc.Options{
+		Fields:  fields,
+		Order:   "write_date, id",
+		Limit:   size,
+		Context: f.context(),
+	})
+	if err != nil {
+		return Batch{}, false, err
+	}
+
+	b = Batch{Model: f.Model, Checkpoint: Checkpoint{
+		WriteDate: cp.WriteDate,
+		Seen:      slices.Clone(cp.Seen),
+		Known:     cp.Known,
+	}}
+	for _, rec := range recs {
+		id := recordID(rec)
+		writeDate, _ := rec["write_date"].(string)
+		createDate, _ := rec["create_date"].(string)
+
+		ev := Event{Op: Updated, ID: id, Record: rec}
+		if cp.WriteDate == "" || createDate > cp.WriteDate ||
+			(createDate == cp.WriteDate && !slices.Contains(cp.Seen, id)) {
+			ev.Op = Created
+		}
+		if active, ok := rec["active"].(bool); ok && f.Deletes == DeleteArchived && !active {
+			ev = Event{Op: Deleted, ID: id}
+		}
+		b.Events = append(b.Events, ev)
+
+		if writeDate != b.Checkpoint.WriteDate {
+			b.Checkpoint.WriteDate = writeDate
+			b.Checkpoint.Seen = nil
+		}
+		b.Checkpoint.Seen = append(b.Checkpoint.Seen, id)
+	}
+	return b, len(recs) == size, nil
+}
+
+// deletions compares the ids matching the domain with the known ones.
+func (f *Feed) deletions(ctx context.Context, cp Checkpoint) (Batch, error) {
+	domain := f.Domain
+	if domain == nil {
+		domain = odoorpc.NewDomain()
+	}
+	ids, err := f.Client.Search(ctx, f.Model, domain, odoorpc.Options{Order: "id", Context: f.context()})
+	if err != nil {
+		return Batch{}, err
+	}
+	slices.Sort(ids)
+	b := Batch{Model: f.Model, Checkpoint: cp}
+	b.Checkpoint.Known = ids
+	for _, id := range cp.Known {
+		if _, found := slices.BinarySearch(ids, id); !found {
+			b.Events = append(b.Events, Event{Op: Deleted, ID: id})
+		}
+	}
+	return b, nil
+}
+
+func recordID(rec map[string]any) int64 {
+	switch id := rec["id"].(type) {
+	case float64:
+		return int64(id)
+	case int64:
+		return id
+	}
+	return 0
+}
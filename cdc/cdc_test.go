@@ -0,0 +1,212 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// tableClient serves SearchRead and Search over an in-memory table,
+// evaluating the domains built by Feed.
+type tableClient struct {
+	odoorpc.Client
+	records map[int64]map[string]any
+}
+
+func (c *tableClient) match(ctx map[string]any, domain odoorpc.Domain, rec map[string]any) bool {
+	if active, ok := rec["active"].(bool); ok && !active && ctx["active_test"] != false {
+		return false
+	}
+	var stack []bool
+	for i := len(domain) - 1; i >= 0; i-- {
+		switch item := domain[i].(type) {
+		case string:
+			a, b := stack[len(stack)-1], stack[len(stack)-2]
+			stack = stack[:len(stack)-2]
+			if item == "|" {
+				stack = append(stack, a || b)
+			} else {
+				stack = append(stack, a && b)
+			}
+		case []any:
+			stack = append(stack, leaf(item, rec))
+		}
+	}
+	for _, ok := range stack {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func leaf(cond []any, rec map[string]any) bool {
+	field, op := cond[0].(string), cond[1].(string)
+	switch op {
+	case ">=":
+		return rec[field].(string) >= cond[2].(string)
+	case "not in":
+		return !slices.Contains(cond[2].([]int64), int64(rec["id"].(float64)))
+	}
+	panic("unsupported operator " + op)
+}
+
+func (c *tableClient) sorted(ctx map[string]any, domain odoorpc.Domain) []map[string]any {
+	var recs []map[string]any
+	for _, rec := range c.records {
+		if c.match(ctx, domain, rec) {
+			recs = append(recs, rec)
+		}
+	}
+	slices.SortFunc(recs, func(a, b map[string]any) int {
+		if a["write_date"] != b["write_date"] {
+			if a["write_date"].(string) < b["write_date"].(string) {
+				return -1
+			}
+			return 1
+		}
+		return int(a["id"].(float64) - b["id"].(float64))
+	})
+	return recs
+}
+
+func (c *tableClient) SearchRead(_ context.Context, model string, domain odoorpc.Domain, opts odoorpc.Options) ([]map[string]any, error) {
+	recs := c.sorted(opts.Context, domain)
+	if len(recs) > opts.Limit {
+		recs = recs[:opts.Limit]
+	}
+	return recs, nil
+}
+
+func (c *tableClient) Search(_ context.Context, model string, domain odoorpc.Domain, opts odoorpc.Options) ([]int64, error) {
+	var ids []int64
+	for _, rec := range c.sorted(opts.Context, domain) {
+		ids = append(ids, int64(rec["id"].(float64)))
+	}
+	return ids, nil
+}
+
+func (c *tableClient) put(id int64, created, written string) {
+	c.records[id] = map[string]any{"id": float64(id), "create_date": created, "write_date": written, "active": true}
+}
+
+type collector struct {
+	events []Event
+	fail   bool
+}
+
+func (c *collector) handle(_ context.Context, b Batch) error {
+	if c.fail {
+		return errors.New("warehouse down")
+	}
+	c.events = append(c.events, b.Events...)
+	return nil
+}
+
+func (c *collector) take() []string {
+	var out []string
+	for _, ev := range c.events {
+		out = append(out, string(ev.Op)+":"+string(rune('0'+ev.ID)))
+	}
+	c.events = nil
+	return out
+}
+
+func TestFeedHandlesSharedTimestampsAndResumes(t *testing.T) {
+	client := &tableClient{records: map[int64]map[string]any{}}
+	for id := int64(1); id <= 5; id++ {
+		client.put(id, "2024-01-01 10:00:00", "2024-01-01 10:00:00")
+	}
+	store := NewMemoryStore()
+	feed := &Feed{Client: client, Model: "res.partner", BatchSize: 2, Store: store}
+	ctx := context.Background()
+	var got collector
+
+	n, err := feed.Poll(ctx, got.handle)
+	if err != nil || n != 5 {
+		t.Fatalf("Poll = %d, %v", n, err)
+	}
+	if want := []string{"created:1", "created:2", "created:3", "created:4", "created:5"}; !reflect.DeepEqual(got.take(), want) {
+		t.Fatalf("unexpected first poll")
+	}
+
+	client.put(6, "2024-01-01 10:00:00", "2024-01-01 10:00:00")
+	client.records[2]["write_date"] = "2024-01-01 10:05:00"
+	if _, err := feed.Poll(ctx, got.handle); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"created:6", "updated:2"}; !reflect.DeepEqual(got.take(), want) {
+		t.Errorf("unexpected second poll, want %v", want)
+	}
+
+	// A failing handler leaves the checkpoint in place.
+	client.records[3]["write_date"] = "2024-01-01 10:06:00"
+	got.fail = true
+	if _, err := feed.Poll(ctx, got.handle); err == nil {
+		t.Fatal("expected handler error")
+	}
+	got.fail = false
+	restarted := &Feed{Client: client, Model: "res.partner", Store: store}
+	if _, err := restarted.Poll(ctx, got.handle); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"updated:3"}; !reflect.DeepEqual(got.take(), want) {
+		t.Errorf("batch should be redelivered after a failure")
+	}
+}
+
+func TestFeedDetectsDeletions(t *testing.T) {
+	ctx := context.Background()
+	t.Run("id set", func(t *testing.T) {
+		client := &tableClient{records: map[int64]map[string]any{}}
+		client.put(1, "2024-01-01 10:00:00", "2024-01-01 10:00:00")
+		client.put(2, "2024-01-01 10:00:00", "2024-01-01 10:00:00")
+		feed := &Feed{Client: client, Model: "res.partner", Deletes: DeleteByIDSet}
+		var got collector
+		feed.Poll(ctx, got.handle)
+		got.take()
+		delete(client.records, 1)
+		if _, err := feed.Poll(ctx, got.handle); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"deleted:1"}; !reflect.DeepEqual(got.take(), want) {
+			t.Errorf("expected deletion of 1")
+		}
+	})
+	t.Run("archived", func(t *testing.T) {
+		client := &tableClient{records: map[int64]map[string]any{}}
+		client.put(1, "2024-01-01 10:00:00", "2024-01-01 10:00:00")
+		feed := &Feed{Client: client, Model: "res.partner", Deletes: DeleteArchived}
+		var got collector
+		feed.Poll(ctx, got.handle)
+		got.take()
+		client.records[1]["active"] = false
+		client.records[1]["write_date"] = "2024-01-02 08:00:00"
+		if _, err := feed.Poll(ctx, got.handle); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"deleted:1"}; !reflect.DeepEqual(got.take(), want) {
+			t.Errorf("archived record should be reported as deleted")
+		}
+	})
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileStore(t.TempDir())
+	if _, found, err := store.Load(ctx, "res.partner"); err != nil || found {
+		t.Fatalf("Load on empty store = %v, %v", found, err)
+	}
+	cp := Checkpoint{WriteDate: "2024-01-01 10:00:00", Seen: []int64{4, 5}}
+	if err := store.Save(ctx, "res.partner", cp); err != nil {
+		t.Fatal(err)
+	}
+	got, found, err := store.Load(ctx, "res.partner")
+	if err != nil || !found || !reflect.DeepEqual(got, cp) {
+		t.Errorf("Load = %+v, %v, %v", got, found, err)
+	}
+}
@@ -0,0 +1,105 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Store persists feed checkpoints.
+type Store interface {
+	// Load returns the checkpoint saved under key; found is false when
+	// there is none.
+	Load(ctx context.Context, key string) (cp Checkpoint, found bool, err error)
+	// Save durably replaces the checkpoint saved under key.
+	Save(ctx context.Context, key string, cp Checkpoint) error
+}
+
+// MemoryStore keeps checkpoints in memory.
+type MemoryStore struct {
+	mu  sync.Mutex
+	cps map[string]Checkpoint
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{cps: map[string]Checkpoint{}}
+}
+
+func (s *MemoryStore) Load(_ context.Context, key string) (Checkpoint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.cps[key]
+	return cp, ok, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, key string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cps[key] = cp
+	return nil
+}
+
+// FileStore keeps each checkpoint in a JSON file of a directory. Files are
+// replaced atomically so a crash never leaves a truncated checkpoint.
+type FileStore struct {
+	Dir string
+}
+
+// NewFileStore returns a FileStore writing to dir, which is created on the
+// first save.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{Dir: dir}
+}
+
+func (s *FileStore) path(key string) string {
+	name := strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(key)
+	return filepath.Join(s.Dir, name+".json")
+}
+
+func (s *FileStore) Load(_ context.Context, key string) (Checkpoint, bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, false, err
+	}
+	return cp, true, nil
+}
+
+func (s *FileStore) Save(_ context.Context, key string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
@@ -0,0 +1,348 @@
+package odoorpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Subtypes of chatter messages, by external id.
+const (
+	SubtypeComment = "mail.mt_comment"
+	SubtypeNote    = "mail.mt_note"
+)
+
+// MessageOptions controls PostMessage.
+type MessageOptions struct {
+	Subject string
+	// Subtype is the external id of the message subtype; it defaults to
+	// SubtypeComment, which notifies the followers.
+	Subtype string
+	// MessageType defaults to "comment".
+	MessageType string
+	// PartnerIDs are notified in addition to the followers.
+	PartnerIDs []int64
+	// AttachmentIDs are attachments to link to the message, such as those
+	// returned by UploadAttachment.
+	AttachmentIDs []int64
+	Context       map[string]any
+}
+
+// PostMessage posts a message in the chatter of a record with
+// `message_post` and returns the id of the mail.message.
+//
+// Odoo 17 and later escape bodies posted over RPC, so body is shown as
+// plain text there; older versions render it as HTML.
+func (c *RpcClient) PostMessage(ctx context.Context, model string, id int64, body string, opts MessageOptions) (int64, error) {
+	kwargs := Options{Context: opts.Context}.Kwargs()
+	kwargs["body"] = body
+	kwargs["message_type"] = opts.MessageType
+	if opts.MessageType == "" {
+		kwargs["message_type"] = "comment"
+	}
+	kwargs["subtype_xmlid"] = opts.Subtype
+	if opts.Subtype == "" {
+		kwargs["subtype_xmlid"] = SubtypeComment
+	}
+	if opts.Subject != "" {
+		kwargs["subject"] = opts.Subject
+	}
+	if len(opts.PartnerIDs) > 0 {
+		kwargs["partner_ids"] = opts.PartnerIDs
+	}
+	if len(opts.AttachmentIDs) > 0 {
+		kwargs["attachment_ids"] = opts.AttachmentIDs
+	}
+	var messageID int64
+	if err := c.executeKw(ctx, model, "message_post", []any{[]int64{id}}, kwargs, &messageID); err != nil {
+		return 0, err
+	}
+	return messageID, nil
+}
+
+// PostNote posts an internal note, visible to employees and not sent to
+// the followers.
+func (c *RpcClient) PostNote(ctx context.Context, model string, id int64, body string, opts MessageOptions) (int64, error) {
+	opts.Subtype = SubtypeNote
+	return c.PostMessage(ctx, model, id, body, opts)
+}
+
+// Follow subscribes partners to the records, with the default subtypes of
+// the model when subtypeIDs is empty.
+func (c *RpcClient) Follow(ctx context.Context, model string, ids, partnerIDs, subtypeIDs []int64) error {
+	kwargs := map[string]any{"partner_ids": partnerIDs}
+	if len(subtypeIDs) > 0 {
+		kwargs["subtype_ids"] = subtypeIDs
+	}
+	return c.executeKw(ctx, model, "message_subscribe", []any{ids}, kwargs, nil)
+}
+
+// Unfollow unsubscribes partners from the records.
+func (c *RpcClient) Unfollow(ctx context.Context, model string, ids, partnerIDs []int64) error {
+	return c.executeKw(ctx, model, "message_unsubscribe", []any{ids}, map[string]any{"partner_ids": partnerIDs}, nil)
+}
+
+// Follower is a partner following a record.
+type Follower struct {
+	ID          int64
+	PartnerID   int64
+	PartnerName string
+	SubtypeIDs  []int64
+}
+
+// Followers returns the followers of a record.
+func (c *RpcClient) Followers(ctx context.Context, model string, id int64) ([]Follower, error) {
+	domain := NewDomain().Equals("res_model", model).Equals("res_id", id)
+	recs, err := c.SearchRead(ctx, "mail.followers", domain, Options{Fields: []string{"partner_id", "subtype_ids"}})
+	if err != nil {
+		return nil, err
+	}
+	followers := make([]Follower, len(recs))
+	for i, rec := range recs {
+		fid, _ := rec["id"].(float64)
+		followers[i] = Follower{
+			ID:          int64(fid),
+			PartnerID:   many2oneID(rec["partner_id"]),
+			PartnerName: many2oneName(rec["partner_id"]),
+			SubtypeIDs:  int64List(rec["subtype_ids"]),
+		}
+	}
+	return followers, nil
+}
+
+// Message is a chatter message.
+type Message struct {
+	ID int64
+	// Date is the UTC posting date, "2006-01-02 15:04:05".
+	Date        string
+	Body        string
+	Subject     string
+	MessageType string
+	Subtype     string
+	AuthorID    int64
+	AuthorName  string
+	// Tracking lists the field changes logged with the message.
+	Tracking      []TrackingValue
+	AttachmentIDs []int64
+}
+
+// TrackingValue is a field change logged in the chatter.
+type TrackingValue struct {
+	// Field is the label of the changed field.
+	Field    string
+	OldValue any
+	NewValue any
+}
+
+var messageFields = []string{"date", "body", "subject", "message_type", "subtype_id", "author_id", "tracking_value_ids", "attachment_ids"}
+
+// Messages returns the chatter of a record, newest first. opts.Limit and
+// opts.Offset page through long threads.
+func (c *RpcClient) Messages(ctx context.Context, model string, id int64, opts Options) ([]Message, error) {
+	domain := NewDomain().Equals("model", model).Equals("res_id", id)
+	recs, err := c.SearchRead(ctx, "mail.message", domain, Options{
+		Fields:  messageFields,
+		Order:   "id desc",
+		Limit:   opts.Limit,
+		Offset:  opts.Offset,
+		Context: opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, len(recs))
+	var trackingIDs []int64
+	for i, rec := range recs {
+		mid, _ := rec["id"].(float64)
+		messages[i] = Message{
+			ID:            int64(mid),
+			Date:          stringValue(rec["date"]),
+			Body:          stringValue(rec["body"]),
+			Subject:       stringValue(rec["subject"]),
+			MessageType:   stringValue(rec["message_type"]),
+			Subtype:       many2oneName(rec["subtype_id"]),
+			AuthorID:      many2oneID(rec["author_id"]),
+			AuthorName:    many2oneName(rec["author_id"]),
+			AttachmentIDs: int64List(rec["attachment_ids"]),
+		}
+		trackingIDs = append(trackingIDs, int64List(rec["tracking_value_ids"])...)
+	}
+	if len(trackingIDs) == 0 {
+		return messages, nil
+	}
+	tracking, err := c.trackingValues(ctx, trackingIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].Tracking = tracking[messages[i].ID]
+	}
+	return messages, nil
+}
+
+// trackingValues reads mail.tracking.value records grouped by message id.
+// The model changed across versions (field/field_id, field_desc, typed
+// value columns), so only the fields present on the server are read.
+func (c *RpcClient) trackingValues(ctx context.Context, ids []int64) (map[int64][]TrackingValue, error) {
+	meta, err := c.fieldsMeta(ctx, "mail.tracking.value")
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for _, f := range []string{"mail_message_id", "field", "field_id", "field_desc", "field_type",
+		"old_value_char", "new_value_char", "old_value_text", "new_value_text",
+		"old_value_datetime", "new_value_datetime", "old_value_float", "new_value_float",
+		"old_value_monetary", "new_value_monetary", "old_value_integer", "new_value_integer"} {
+		if _, ok := meta[f]; ok {
+			fields = append(fields, f)
+		}
+	}
+	recs, err := c.Read(ctx, "mail.tracking.value", ids, Options{Fields: fields})
+	if err != nil {
+		return nil, err
+	}
+	byMessage := map[int64][]TrackingValue{}
+	for _, rec := range recs {
+		tv := TrackingValue{Field: stringValue(rec["field_desc"])}
+		if tv.Field == "" {
+			tv.Field = many2oneName(rec["field_id"])
+		}
+		if tv.Field == "" {
+			tv.Field = many2oneName(rec["field"])
+		}
+		tv.OldValue = trackedValue(rec, "old", stringValue(rec["field_type"]))
+		tv.NewValue = trackedValue(rec, "new", stringValue(rec["field_type"]))
+		msg := many2oneID(rec["mail_message_id"])
+		byMessage[msg] = append(byMessage[msg], tv)
+	}
+	return byMessage, nil
+}
+
+// trackedValue returns the old or new value of a tracking record from the
+// column matching the field type, or from the first filled one.
+func trackedValue(rec map[string]any, prefix, fieldType string) any {
+	column := map[string]string{
+		"char": "char", "selection": "char", "many2one": "char", "many2many": "char", "one2many": "char",
+		"text": "text", "html": "text",
+		"date": "datetime", "datetime": "datetime",
+		"float": "float", "monetary": "monetary",
+		"integer": "integer", "boolean": "integer",
+	}[fieldType]
+	if column != "" {
+		return rec[prefix+"_value_"+column]
+	}
+	for _, col := range []string{"char", "text", "datetime", "monetary", "float"} {
+		if v, ok := rec[prefix+"_value_"+col]; ok && v != false && v != 0.0 {
+			return v
+		}
+	}
+	return rec[prefix+"_value_integer"]
+}
+
+// Activity is a scheduled mail.activity.
+type Activity struct {
+	ID       int64
+	Type     string
+	TypeID   int64
+	Summary  string
+	Note     string
+	Deadline string
+	UserID   int64
+	// State is "overdue", "today" or "planned".
+	State string
+}
+
+// ActivityOptions describes an activity to schedule.
+type ActivityOptions struct {
+	// Type is the external id of the activity type, e.g.
+	// "mail.mail_activity_data_todo" or "mail.mail_activity_data_call".
+	Type    string
+	Summary string
+	Note    string
+	// Deadline defaults to today.
+	Deadline time.Time
+	// UserID is the assignee; it defaults to the current user.
+	UserID int64
+}
+
+// ScheduleActivity creates an activity on a record and returns its id.
+func (c *RpcClient) ScheduleActivity(ctx context.Context, model string, id int64, opts ActivityOptions) (int64, error) {
+	if opts.Type == "" {
+		opts.Type = "mail.mail_activity_data_todo"
+	}
+	activityType, err := c.ResolveXMLID(ctx, opts.Type)
+	if err != nil {
+		return 0, err
+	}
+	if activityType.Model != "mail.activity.type" {
+		return 0, fmt.Errorf("%s is a %s, not an activity type", opts.Type, activityType.Model)
+	}
+	models, err := c.Search(ctx, "ir.model", NewDomain().Equals("model", model), Options{Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	if len(models) == 0 {
+		return 0, fmt.Errorf("model %s not found", model)
+	}
+	values := map[string]any{
+		"res_model_id":     models[0],
+		"res_id":           id,
+		"activity_type_id": activityType.ID,
+	}
+	if opts.Summary != "" {
+		values["summary"] = opts.Summary
+	}
+	if opts.Note != "" {
+		values["note"] = opts.Note
+	}
+	if !opts.Deadline.IsZero() {
+		values["date_deadline"] = opts.Deadline.Format(time.DateOnly)
+	}
+	if opts.UserID != 0 {
+		values["user_id"] = opts.UserID
+	}
+	return c.Create(ctx, "mail.activity", values)
+}
+
+// Activities returns the pending activities of a record, by deadline.
+func (c *RpcClient) Activities(ctx context.Context, model string, id int64) ([]Activity, error) {
+	domain := NewDomain().Equals("res_model", model).Equals("res_id", id)
+	recs, err := c.SearchRead(ctx, "mail.activity", domain, Options{
+		Fields: []string{"activity_type_id", "summary", "note", "date_deadline", "user_id", "state"},
+		Order:  "date_deadline, id",
+	})
+	if err != nil {
+		return nil, err
+	}
+	activities := make([]Activity, len(recs))
+	for i, rec := range recs {
+		aid, _ := rec["id"].(float64)
+		activities[i] = Activity{
+			ID:       int64(aid),
+			Type:     many2oneName(rec["activity_type_id"]),
+			TypeID:   many2oneID(rec["activity_type_id"]),
+			Summary:  stringValue(rec["summary"]),
+			Note:     stringValue(rec["note"]),
+			Deadline: stringValue(rec["date_deadline"]),
+			UserID:   many2oneID(rec["user_id"]),
+			State:    stringValue(rec["state"]),
+		}
+	}
+	return activities, nil
+}
+
+// CompleteActivity marks an activity as done with an optional feedback,
+// which Odoo logs in the chatter. It returns the id of the logged message,
+// or zero.
+func (c *RpcClient) CompleteActivity(ctx context.Context, activityID int64, feedback string) (int64, error) {
+	kwargs := map[string]any{}
+	if feedback != "" {
+		kwargs["feedback"] = feedback
+	}
+	var res any
+	if err := c.executeKw(ctx, "mail.activity", "action_feedback", []any{[]int64{activityID}}, kwargs, &res); err != nil {
+		return 0, err
+	}
+	messageID, _ := res.(float64)
+	return int64(messageID), nil
+}
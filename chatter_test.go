@@ -0,0 +1,124 @@
+package odoorpc_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestPostNoteAndFollowers(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "message_post":
+			return 99, nil
+		case "message_subscribe":
+			return true, nil
+		}
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	id, err := c.PostNote(ctx, "sale.order", 7, "Synced from WMS", odoorpc.MessageOptions{PartnerIDs: []int64{3}, AttachmentIDs: []int64{12}})
+	if err != nil || id != 99 {
+		t.Fatalf("PostNote = %d, %v", id, err)
+	}
+	post := srv.Calls[0]
+	if !reflect.DeepEqual(post.Args, []any{[]any{float64(7)}}) {
+		t.Errorf("message_post args = %v", post.Args)
+	}
+	want := map[string]any{
+		"body": "Synced from WMS", "message_type": "comment", "subtype_xmlid": "mail.mt_note",
+		"partner_ids": []any{float64(3)}, "attachment_ids": []any{float64(12)},
+	}
+	if !reflect.DeepEqual(post.Kwargs, want) {
+		t.Errorf("message_post kwargs = %v", post.Kwargs)
+	}
+
+	if err := c.Follow(ctx, "sale.order", []int64{7}, []int64{3, 4}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.Calls[1].Kwargs["partner_ids"]; !reflect.DeepEqual(got, []any{float64(3), float64(4)}) {
+		t.Errorf("message_subscribe partner_ids = %v", got)
+	}
+}
+
+func TestMessagesWithTracking(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Model == "mail.message":
+			return []any{map[string]any{
+				"id": 50, "date": "2024-05-01 10:00:00", "body": "", "subject": false, "message_type": "notification",
+				"subtype_id": []any{2, "Note"}, "author_id": []any{3, "Mitchell Admin"},
+				"tracking_value_ids": []any{70}, "attachment_ids": []any{},
+			}}, nil
+		case call.Method == "fields_get":
+			return map[string]any{
+				"mail_message_id": map[string]any{"type": "many2one"},
+				"field_id":        map[string]any{"type": "many2one"},
+				"old_value_char":  map[string]any{"type": "char"},
+				"new_value_char":  map[string]any{"type": "char"},
+				"old_value_float": map[string]any{"type": "float"},
+				"new_value_float": map[string]any{"type": "float"},
+			}, nil
+		case call.Model == "mail.tracking.value":
+			return []any{map[string]any{
+				"id": 70, "mail_message_id": []any{50, "msg"}, "field_id": []any{9, "Status"},
+				"old_value_char": "Quotation", "new_value_char": "Sales Order", "old_value_float": 0, "new_value_float": 0,
+			}}, nil
+		}
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	msgs, err := c.Messages(context.Background(), "sale.order", 7, odoorpc.Options{Limit: 10})
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].AuthorName != "Mitchell Admin" || msgs[0].Subtype != "Note" {
+		t.Fatalf("unexpected messages %+v", msgs)
+	}
+	want := []odoorpc.TrackingValue{{Field: "Status", OldValue: "Quotation", NewValue: "Sales Order"}}
+	if !reflect.DeepEqual(msgs[0].Tracking, want) {
+		t.Errorf("tracking = %+v", msgs[0].Tracking)
+	}
+}
+
+func TestScheduleAndCompleteActivity(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Model == "ir.model.data":
+			return []any{map[string]any{"module": "mail", "name": "mail_activity_data_call", "model": "mail.activity.type", "res_id": 2}}, nil
+		case call.Model == "ir.model":
+			return []any{140}, nil
+		case call.Method == "create":
+			return 31, nil
+		case call.Method == "action_feedback":
+			return 51, nil
+		}
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	id, err := c.ScheduleActivity(ctx, "res.partner", 7, odoorpc.ActivityOptions{
+		Type:     "mail.mail_activity_data_call",
+		Summary:  "Call back",
+		Deadline: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil || id != 31 {
+		t.Fatalf("ScheduleActivity = %d, %v", id, err)
+	}
+	values := srv.Calls[2].Args[0].(map[string]any)
+	want := map[string]any{"res_model_id": float64(140), "res_id": float64(7), "activity_type_id": float64(2), "summary": "Call back", "date_deadline": "2024-06-03"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("activity values = %v", values)
+	}
+	msg, err := c.CompleteActivity(ctx, 31, "Customer agreed")
+	if err != nil || msg != 51 {
+		t.Errorf("CompleteActivity = %d, %v", msg, err)
+	}
+	if got := srv.Calls[3].Kwargs["feedback"]; got != "Customer agreed" {
+		t.Errorf("feedback = %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package odoorpc
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/Guadalsistema/odoorpc/jsonrpc"
@@ -9,15 +10,37 @@ import (
 
 // RpcClient implements the Client interface using the JSON-RPC API.
 type RpcClient struct {
-	rpc      *jsonrpc.NetClient
-	db       string
-	uid      int64
-	password string
+	rpc   *jsonrpc.NetClient
+	db    string
+	uid   int64
+	creds CredentialProvider
+	// login is the username given to AuthenticateWith, used to open a web
+	// session on demand for routes that need one.
+	login string
+	// session routes ORM calls through the cookie authenticated
+	// /web/dataset/call_kw endpoint instead of object.execute_kw.
+	session bool
+	// context is merged into the context of every ORM call.
+	context map[string]any
+	// meta caches server metadata; it is shared with derived clients.
+	meta *serverMeta
 }
 
 // New creates a new RPCClient using the provided url and database name.
 func New(url string, httpClient *http.Client) *RpcClient {
-	return &RpcClient{rpc: jsonrpc.New(url, httpClient)}
+	return &RpcClient{rpc: jsonrpc.New(url, httpClient), meta: &serverMeta{}}
+}
+
+// Transport returns the JSON-RPC transport of the client, which holds the
+// session cookies after SessionAuthenticate.
+func (c *RpcClient) Transport() *jsonrpc.NetClient {
+	return c.rpc
+}
+
+// UID returns the id of the authenticated user, or zero before
+// authentication.
+func (c *RpcClient) UID() int64 {
+	return c.uid
 }
 
 // Version get metadata call
@@ -35,20 +58,48 @@ func (c *RpcClient) Version(ctx context.Context) (ServerVersion, error) {
 }
 
 // Authenticate logs in the user and returns its uid.
+//
+// password may be the user password or an API key (Preferences > Account
+// Security > New API Key). Users with two-factor authentication enabled can
+// only log in over RPC with an API key, or through SessionAuthenticate.
+//
+// The password is kept by the client for the following calls; use
+// AuthenticateWith to supply it through a CredentialProvider instead.
 func (c *RpcClient) Authenticate(ctx context.Context, username, password, db string) (int64, error) {
+	return c.AuthenticateWith(ctx, username, db, StaticCredentials(password))
+}
+
+// AuthenticateWith logs in the user with the secret returned by creds and
+// returns its uid. The provider is asked again for every following call, so
+// rotated secrets are picked up without recreating the client.
+func (c *RpcClient) AuthenticateWith(ctx context.Context, username, db string, creds CredentialProvider) (int64, error) {
+	password, err := creds.Secret(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("credentials: %w", err)
+	}
 	params := map[string]any{
 		"service": "common",
 		"method":  "login",
 		"args":    []any{db, username, password},
 	}
-	var uid int64
-	if err := c.rpc.Call(ctx, "call", params, &uid); err != nil {
+	// Odoo answers false instead of an error for rejected credentials.
+	var res any
+	if err := c.rpc.Call(ctx, "call", params, &res); err != nil {
 		return 0, err
 	}
-	c.password = password
-	c.uid = uid
+	uid, ok := res.(float64)
+	if !ok || uid <= 0 {
+		if LooksLikeAPIKey(password) {
+			return 0, fmt.Errorf("%w: API key rejected for %s", ErrAuthenticationFailed, username)
+		}
+		return 0, fmt.Errorf("%w for %s (users with two-factor authentication need an API key)", ErrAuthenticationFailed, username)
+	}
+	c.creds = creds
+	c.login = username
+	c.session = false
+	c.uid = int64(uid)
 	c.db = db
-	return uid, nil
+	return c.uid, nil
 }
 
 // SearchRead queries an Odoo model using the `execute_kw` RPC call with the `search_read` method.
@@ -88,13 +139,8 @@ func (c *RpcClient) SearchRead(ctx context.Context, model string, domain Domain,
 		domain = Domain{}
 	}
 	args := []any{domain}
-	params := map[string]any{
-		"service": "object",
-		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, "search_read", args, opts.Kwargs()},
-	}
 	var res []map[string]any
-	if err := c.rpc.Call(ctx, "call", params, &res); err != nil {
+	if err := c.executeKw(ctx, model, "search_read", args, opts.Kwargs(), &res); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -109,13 +155,8 @@ func (c *RpcClient) FieldsGet(ctx context.Context, model string, fields []string
 		fields = []string{}
 	}
 	args := []any{fields}
-	params := map[string]any{
-		"service": "object",
-		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, "fields_get", args, opts.Kwargs()},
-	}
 	var res map[string]any
-	if err := c.rpc.Call(ctx, "call", params, &res); err != nil {
+	if err := c.executeKw(ctx, model, "fields_get", args, opts.Kwargs(), &res); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -126,27 +167,30 @@ func (c *RpcClient) Search(ctx context.Context, model string, domain Domain, opt
 		domain = Domain{}
 	}
 	args := []any{domain}
-	params := map[string]any{
-		"service": "object",
-		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, "search", args, opts.Kwargs()},
-	}
 	var res []int64
-	if err := c.rpc.Call(ctx, "call", params, &res); err != nil {
+	if err := c.executeKw(ctx, model, "search", args, opts.Kwargs(), &res); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
+// SearchCount returns the number of records of a model matching domain.
+func (c *RpcClient) SearchCount(ctx context.Context, model string, domain Domain, opts Options) (int64, error) {
+	if domain == nil {
+		domain = Domain{}
+	}
+	kwargs := Options{Context: opts.Context, Limit: opts.Limit}.Kwargs()
+	var res int64
+	if err := c.executeKw(ctx, model, "search_count", []any{domain}, kwargs, &res); err != nil {
+		return 0, err
+	}
+	return res, nil
+}
+
 // Create adds a new record to the given model and returns its ID.
 func (c *RpcClient) Create(ctx context.Context, model string, values map[string]any) (int64, error) {
-	params := map[string]any{
-		"service": "object",
-		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, "create", []any{values}},
-	}
 	var id int64
-	if err := c.rpc.Call(ctx, "call", params, &id); err != nil {
+	if err := c.executeKw(ctx, model, "create", []any{values}, nil, &id); err != nil {
 		return 0, err
 	}
 	return id, nil
@@ -154,13 +198,8 @@ func (c *RpcClient) Create(ctx context.Context, model string, values map[string]
 
 // Update modifies fields for the specified records of a model.
 func (c *RpcClient) Update(ctx context.Context, model string, ids []int64, values map[string]any) (bool, error) {
-	params := map[string]any{
-		"service": "object",
-		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, "write", []any{ids, values}},
-	}
 	var res bool
-	if err := c.rpc.Call(ctx, "call", params, &res); err != nil {
+	if err := c.executeKw(ctx, model, "write", []any{ids, values}, nil, &res); err != nil {
 		return false, err
 	}
 	return res, nil
@@ -168,13 +207,8 @@ func (c *RpcClient) Update(ctx context.Context, model string, ids []int64, value
 
 // Unlink removes records from a model.
 func (c *RpcClient) Unlink(ctx context.Context, model string, ids []int64) (bool, error) {
-	params := map[string]any{
-		"service": "object",
-		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, "unlink", []any{ids}},
-	}
 	var res bool
-	if err := c.rpc.Call(ctx, "call", params, &res); err != nil {
+	if err := c.executeKw(ctx, model, "unlink", []any{ids}, nil, &res); err != nil {
 		return false, err
 	}
 	return res, nil
@@ -190,13 +224,8 @@ func (c *RpcClient) CallMethod(ctx context.Context, model, method string, vars [
 	if vars == nil {
 		vars = []any{}
 	}
-	params := map[string]any{
-		"service": "object",
-		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, method, vars, opts.Kwargs()},
-	}
 	var raw any
-	if err := c.rpc.Call(ctx, "call", params, &raw); err != nil {
+	if err := c.executeKw(ctx, model, method, vars, opts.Kwargs(), &raw); err != nil {
 		return nil, err
 	}
 	switch v := raw.(type) {
@@ -225,17 +254,60 @@ func (c *RpcClient) Read(ctx context.Context, model string, ids []int64, opts Op
 	}
 	args := []any{idArgs}
 
+	var res []map[string]any
+	if err := c.executeKw(ctx, model, "read", args, opts.Kwargs(), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// executeKw runs an ORM method through `object.execute_kw` and decodes the
+// raw result into result.
+func (c *RpcClient) executeKw(ctx context.Context, model, method string, args []any, kwargs map[string]any, result any) error {
+	path, params, err := c.kwRequest(ctx, model, method, args, kwargs)
+	if err != nil {
+		return err
+	}
+	return classifyError(model, c.rpc.CallPath(ctx, path, "call", params, result))
+}
+
+// kwRequest returns the route and JSON-RPC params of an ORM call: the
+// session route after SessionAuthenticate, `object.execute_kw` otherwise.
+func (c *RpcClient) kwRequest(ctx context.Context, model, method string, args []any, kwargs map[string]any) (string, any, error) {
+	if args == nil {
+		args = []any{}
+	}
+	kwargs = c.mergeContext(kwargs)
+	if c.session {
+		return callKwRequest(model, method, args, kwargs)
+	}
+	password, err := c.secret(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	callArgs := []any{c.db, c.uid, password, model, method, args}
+	if len(kwargs) > 0 {
+		callArgs = append(callArgs, kwargs)
+	}
 	params := map[string]any{
 		"service": "object",
 		"method":  "execute_kw",
-		"args":    []any{c.db, c.uid, c.password, model, "read", args, opts.Kwargs()},
+		"args":    callArgs,
 	}
+	return "/jsonrpc", params, nil
+}
 
-	var res []map[string]any
-	if err := c.rpc.Call(ctx, "call", params, &res); err != nil {
-		return nil, err
+// secret returns the current password or API key, or an empty one before
+// authentication.
+func (c *RpcClient) secret(ctx context.Context) (string, error) {
+	if c.creds == nil {
+		return "", nil
 	}
-	return res, nil
+	password, err := c.creds.Secret(ctx)
+	if err != nil {
+		return "", fmt.Errorf("credentials: %w", err)
+	}
+	return password, nil
 }
 
 // Assertion
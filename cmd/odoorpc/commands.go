@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// queryFlags are the flags shared by commands reading records.
+type queryFlags struct {
+	fields  string
+	limit   int
+	offset  int
+	order   string
+	context string
+}
+
+func parseQuery(name string, args []string) (*flag.FlagSet, odoorpc.Options, error) {
+	var q queryFlags
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&q.fields, "fields", "", "comma separated field names")
+	fs.IntVar(&q.limit, "limit", 0, "maximum number of records")
+	fs.IntVar(&q.offset, "offset", 0, "number of records to skip")
+	fs.StringVar(&q.order, "order", "", "sort specification, e.g. \"name desc\"")
+	fs.StringVar(&q.context, "context", "", "JSON object merged into the context")
+	if err := fs.Parse(args); err != nil {
+		return nil, odoorpc.Options{}, usageErrorf("%v", err)
+	}
+	opts := odoorpc.Options{Fields: splitList(q.fields), Limit: q.limit, Offset: q.offset, Order: q.order}
+	if q.context != "" {
+		if err := decodeJSON(q.context, &opts.Context); err != nil {
+			return nil, odoorpc.Options{}, usageErrorf("invalid -context: %v", err)
+		}
+	}
+	return fs, opts, nil
+}
+
+// decodeJSON decodes s keeping numbers as json.Number so integers are sent
+// back to Odoo as integers.
+func decodeJSON(s string, v any) error {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func parseIDs(s string) ([]int64, error) {
+	parts := splitList(s)
+	if len(parts) == 0 {
+		return nil, usageErrorf("no ids given")
+	}
+	ids := make([]int64, len(parts))
+	for i, part := range parts {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, usageErrorf("invalid id %q", part)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// modelAndDomain reads the MODEL [DOMAIN] positional arguments.
+func modelAndDomain(args []string) (string, odoorpc.Domain, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", nil, usageErrorf("expected MODEL [DOMAIN]")
+	}
+	if len(args) == 1 {
+		return args[0], odoorpc.NewDomain(), nil
+	}
+	domain, err := odoorpc.ParseDomain(args[1])
+	if err != nil {
+		return "", nil, usageErrorf("%v", err)
+	}
+	return args[0], domain, nil
+}
+
+func runVersion(env *cmdEnv, args []string) error {
+	v, err := env.client.Version(env.ctx)
+	if err != nil {
+		return err
+	}
+	return env.out.records([]map[string]any{{
+		"server_version":   v.ServerVersion,
+		"server_serie":     v.ServerSerie,
+		"protocol_version": v.ProtocolVersion,
+	}}, []string{"server_version", "server_serie", "protocol_version"})
+}
+
+func runLogin(env *cmdEnv, args []string) error {
+	return env.out.records([]map[string]any{{
+		"db":    env.profile.DB,
+		"login": env.profile.User,
+		"uid":   env.client.UID(),
+	}}, []string{"db", "login", "uid"})
+}
+
+func runSearch(env *cmdEnv, args []string) error {
+	fs, opts, err := parseQuery("search", args)
+	if err != nil {
+		return err
+	}
+	model, domain, err := modelAndDomain(fs.Args())
+	if err != nil {
+		return err
+	}
+	ids, err := env.client.Search(env.ctx, model, domain, opts)
+	if err != nil {
+		return err
+	}
+	return env.out.ids(ids)
+}
+
+func runRead(env *cmdEnv, args []string) error {
+	fs, opts, err := parseQuery("read", args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageErrorf("expected MODEL IDS")
+	}
+	ids, err := parseIDs(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	recs, err := env.client.Read(env.ctx, fs.Arg(0), ids, opts)
+	if err != nil {
+		return err
+	}
+	return env.out.records(recs, opts.Fields)
+}
+
+func runSearchRead(env *cmdEnv, args []string) error {
+	fs, opts, err := parseQuery("search-read", args)
+	if err != nil {
+		return err
+	}
+	model, domain, err := modelAndDomain(fs.Args())
+	if err != nil {
+		return err
+	}
+	recs, err := env.client.SearchRead(env.ctx, model, domain, opts)
+	if err != nil {
+		return err
+	}
+	return env.out.records(recs, opts.Fields)
+}
+
+func runCount(env *cmdEnv, args []string) error {
+	fs, opts, err := parseQuery("count", args)
+	if err != nil {
+		return err
+	}
+	model, domain, err := modelAndDomain(fs.Args())
+	if err != nil {
+		return err
+	}
+	n, err := env.client.SearchCount(env.ctx, model, domain, opts)
+	if err != nil {
+		return err
+	}
+	return env.out.value(n)
+}
+
+func runCreate(env *cmdEnv, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("expected MODEL VALUES")
+	}
+	var values map[string]any
+	if err := decodeJSON(args[1], &values); err != nil {
+		return usageErrorf("invalid VALUES: %v", err)
+	}
+	id, err := env.client.Create(env.ctx, args[0], values)
+	if err != nil {
+		return err
+	}
+	return env.out.value(id)
+}
+
+func runWrite(env *cmdEnv, args []string) error {
+	if len(args) != 3 {
+		return usageErrorf("expected MODEL IDS VALUES")
+	}
+	ids, err := parseIDs(args[1])
+	if err != nil {
+		return err
+	}
+	var values map[string]any
+	if err := decodeJSON(args[2], &values); err != nil {
+		return usageErrorf("invalid VALUES: %v", err)
+	}
+	ok, err := env.client.Update(env.ctx, args[0], ids, values)
+	if err != nil {
+		return err
+	}
+	return env.out.value(ok)
+}
+
+func runUnlink(env *cmdEnv, args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("expected MODEL IDS")
+	}
+	ids, err := parseIDs(args[1])
+	if err != nil {
+		return err
+	}
+	ok, err := env.client.Unlink(env.ctx, args[0], ids)
+	if err != nil {
+		return err
+	}
+	return env.out.value(ok)
+}
+
+func runCall(env *cmdEnv, args []string) error {
+	fs, opts, err := parseQuery("call", args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 2 || fs.NArg() > 3 {
+		return usageErrorf("expected MODEL METHOD [ARGS]")
+	}
+	var vars []any
+	if fs.NArg() == 3 {
+		if err := decodeJSON(fs.Arg(2), &vars); err != nil {
+			return usageErrorf("invalid ARGS: %v", err)
+		}
+	}
+	res, err := env.client.CallMethod(env.ctx, fs.Arg(0), fs.Arg(1), vars, opts)
+	if err != nil {
+		return err
+	}
+	if len(res) == 1 {
+		return env.out.value(res[0])
+	}
+	return env.out.value(res)
+}
+
+func runFields(env *cmdEnv, args []string) error {
+	if len(args) < 1 {
+		return usageErrorf("expected MODEL [FIELD...]")
+	}
+	columns := []string{"name", "type", "string", "relation", "required", "readonly"}
+	res, err := env.client.FieldsGet(env.ctx, args[0], args[1:], odoorpc.Options{})
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(res))
+	for name := range res {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	recs := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		attrs, ok := res[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("unexpected description for field %s", name)
+		}
+		rec := map[string]any{"name": name}
+		for _, col := range columns[1:] {
+			rec[col] = attrs[col]
+		}
+		recs = append(recs, rec)
+	}
+	return env.out.records(recs, columns)
+}
@@ -0,0 +1,191 @@
+// Command odoorpc runs ad-hoc queries against an Odoo server.
+//
+// Usage:
+//
+//	odoorpc [global flags] <command> [command flags] [arguments]
+//
+// Connection settings come from the profile selected with -profile (see
+// package config), overridden by the ODOO_* environment variables and then
+// by the global flags. Domains use Odoo's Python syntax, values are JSON:
+//
+//	odoorpc -db prod search-read -fields name,email res.partner "[('is_company', '=', True)]"
+//	odoorpc -db prod write res.partner 7,8 '{"active": false}'
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/config"
+)
+
+type globalFlags struct {
+	profile  string
+	url      string
+	db       string
+	user     string
+	password string
+	format   string
+}
+
+// cmdEnv is what a command runs with.
+type cmdEnv struct {
+	ctx     context.Context
+	profile config.Profile
+	client  *odoorpc.RpcClient
+	out     *printer
+}
+
+type command struct {
+	usage string
+	help  string
+	// auth commands log in before running.
+	auth bool
+	run  func(env *cmdEnv, args []string) error
+}
+
+var commands = map[string]command{
+	"version":     {usage: "version", help: "show the server version", run: runVersion},
+	"login":       {usage: "login", help: "authenticate and print the user id", auth: true, run: runLogin},
+	"search":      {usage: "search [query flags] MODEL [DOMAIN]", help: "print the ids of matching records", auth: true, run: runSearch},
+	"read":        {usage: "read [query flags] MODEL IDS", help: "read records by comma separated ids", auth: true, run: runRead},
+	"search-read": {usage: "search-read [query flags] MODEL [DOMAIN]", help: "read matching records", auth: true, run: runSearchRead},
+	"count":       {usage: "count [query flags] MODEL [DOMAIN]", help: "count matching records", auth: true, run: runCount},
+	"create":      {usage: "create MODEL VALUES", help: "create a record from a JSON object", auth: true, run: runCreate},
+	"write":       {usage: "write MODEL IDS VALUES", help: "update records with a JSON object", auth: true, run: runWrite},
+	"unlink":      {usage: "unlink MODEL IDS", help: "delete records", auth: true, run: runUnlink},
+	"call":        {usage: "call [query flags] MODEL METHOD [ARGS]", help: "call a model method with a JSON array of arguments", auth: true, run: runCall},
+	"fields":      {usage: "fields MODEL [FIELD...]", help: "describe the fields of a model", auth: true, run: runFields},
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	var g globalFlags
+	fs := flag.NewFlagSet("odoorpc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&g.profile, "profile", "", "connection profile name")
+	fs.StringVar(&g.url, "url", "", "server URL (default http://127.0.0.1:8069)")
+	fs.StringVar(&g.db, "db", "", "database name")
+	fs.StringVar(&g.user, "user", "", "login (default admin)")
+	fs.StringVar(&g.password, "password", "", "password or API key")
+	fs.StringVar(&g.format, "format", "table", "output format: table, json or csv")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "odoorpc: unknown command %q\n", name)
+		fs.Usage()
+		return 2
+	}
+	out, err := newPrinter(g.format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "odoorpc: %v\n", err)
+		return 2
+	}
+
+	profile, err := loadProfile(g)
+	if err != nil {
+		fmt.Fprintf(stderr, "odoorpc: %v\n", err)
+		return 1
+	}
+	env := &cmdEnv{ctx: ctx, profile: profile, client: odoorpc.New(profile.URL, nil), out: out}
+	if cmd.auth {
+		if env.client, err = profile.Connect(ctx, nil); err != nil {
+			fmt.Fprintf(stderr, "odoorpc: login failed: %v\n", err)
+			return 1
+		}
+	}
+	if err := cmd.run(env, fs.Args()[1:]); err != nil {
+		var ue usageError
+		if errors.As(err, &ue) {
+			fmt.Fprintf(stderr, "odoorpc: %v\nusage: odoorpc %s\n", err, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "odoorpc: %s: %v\n", name, err)
+		return 1
+	}
+	if err := out.flush(); err != nil {
+		fmt.Fprintf(stderr, "odoorpc: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "usage: odoorpc [global flags] <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintln(w, "\nglobal flags:")
+	fs.PrintDefaults()
+}
+
+// loadProfile resolves the connection profile and applies the flags on top.
+func loadProfile(g globalFlags) (config.Profile, error) {
+	p, err := config.Load(g.profile)
+	if err != nil {
+		return config.Profile{}, err
+	}
+	if g.url != "" {
+		p.URL = g.url
+	}
+	if g.db != "" {
+		p.DB = g.db
+	}
+	if g.user != "" {
+		p.User = g.user
+	}
+	if g.password != "" {
+		p.Password, p.APIKey = g.password, ""
+	}
+	if p.URL == "" {
+		p.URL = "http://127.0.0.1:8069"
+	}
+	if p.User == "" {
+		p.User = "admin"
+	}
+	return p, nil
+}
+
+// usageError reports wrong command line arguments.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func usageErrorf(format string, args ...any) error {
+	return usageError(fmt.Sprintf(format, args...))
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
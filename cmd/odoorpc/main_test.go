@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any `json:"id"`
+			Params struct {
+				Service string `json:"service"`
+				Method  string `json:"method"`
+				Args    []any  `json:"args"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+			return
+		}
+		var result any
+		switch {
+		case req.Params.Method == "login":
+			result = 2
+		case req.Params.Method == "execute_kw" && req.Params.Args[4] == "search_read":
+			result = []map[string]any{
+				{"id": 1, "name": "Acme", "country_id": []any{68, "Spain"}},
+				{"id": 2, "name": "Globex", "country_id": false},
+			}
+		case req.Params.Method == "execute_kw" && req.Params.Args[4] == "search_count":
+			result = 2
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRunSearchReadCSV(t *testing.T) {
+	t.Setenv("ODOO_CONFIG", t.TempDir()+"/profiles.toml")
+	srv := newTestServer(t)
+	var stdout, stderr bytes.Buffer
+	args := []string{"-url", srv.URL, "-db", "odoo", "-password", "pw", "-format", "csv", "search-read", "-fields", "name,country_id", "res.partner", "[('is_company', '=', True)]"}
+	if code := run(context.Background(), args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	want := "id,name,country_id\n1,Acme,Spain\n2,Globex,\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunLoginPrintsUID(t *testing.T) {
+	t.Setenv("ODOO_CONFIG", t.TempDir()+"/profiles.toml")
+	srv := newTestServer(t)
+	var stdout, stderr bytes.Buffer
+	args := []string{"-url", srv.URL, "-db", "odoo", "-user", "admin", "-password", "pw", "-format", "csv", "login"}
+	if code := run(context.Background(), args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if want := "db,login,uid\nodoo,admin,2\n"; stdout.String() != want {
+		t.Fatalf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	t.Setenv("ODOO_CONFIG", t.TempDir()+"/profiles.toml")
+	srv := newTestServer(t)
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-url", srv.URL, "-db", "odoo", "-password", "pw", "count", "res.partner", "[('x'"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected usage exit code, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "usage: odoorpc count") {
+		t.Fatalf("unexpected stderr: %s", stderr.String())
+	}
+	stderr.Reset()
+	if code := run(context.Background(), []string{"bogus"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected unknown command exit code, got %d", code)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// printer renders command results in the selected format.
+type printer struct {
+	format string
+	w      io.Writer
+	tw     *tabwriter.Writer
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case "table", "json", "csv":
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	p := &printer{format: format, w: w}
+	if format == "table" {
+		p.tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		p.w = p.tw
+	}
+	return p, nil
+}
+
+func (p *printer) flush() error {
+	if p.tw != nil {
+		return p.tw.Flush()
+	}
+	return nil
+}
+
+// records prints one row per record. When columns is empty the record keys
+// are used, with "id" first.
+func (p *printer) records(recs []map[string]any, columns []string) error {
+	if len(columns) == 0 {
+		columns = recordColumns(recs)
+	} else if len(recs) > 0 {
+		if _, ok := recs[0]["id"]; ok && columns[0] != "id" {
+			columns = append([]string{"id"}, columns...)
+		}
+	}
+	switch p.format {
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(recs)
+	case "csv":
+		w := csv.NewWriter(p.w)
+		w.Write(columns)
+		for _, rec := range recs {
+			row := make([]string, len(columns))
+			for i, col := range columns {
+				row[i] = cell(rec[col])
+			}
+			w.Write(row)
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		for i, col := range columns {
+			if i > 0 {
+				fmt.Fprint(p.w, "\t")
+			}
+			fmt.Fprint(p.w, col)
+		}
+		fmt.Fprintln(p.w)
+		for _, rec := range recs {
+			for i, col := range columns {
+				if i > 0 {
+					fmt.Fprint(p.w, "\t")
+				}
+				fmt.Fprint(p.w, cell(rec[col]))
+			}
+			fmt.Fprintln(p.w)
+		}
+		return nil
+	}
+}
+
+func (p *printer) ids(ids []int64) error {
+	if p.format == "json" {
+		return json.NewEncoder(p.w).Encode(ids)
+	}
+	if p.format == "csv" {
+		fmt.Fprintln(p.w, "id")
+	}
+	for _, id := range ids {
+		fmt.Fprintln(p.w, id)
+	}
+	return nil
+}
+
+func (p *printer) value(v any) error {
+	if p.format == "json" {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	switch v := v.(type) {
+	case bool:
+		fmt.Fprintln(p.w, strconv.FormatBool(v))
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(p.w, string(data))
+	default:
+		fmt.Fprintln(p.w, cell(v))
+	}
+	return nil
+}
+
+func recordColumns(recs []map[string]any) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, rec := range recs {
+		for key := range rec {
+			if !seen[key] && key != "id" {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	if len(recs) > 0 {
+		if _, ok := recs[0]["id"]; ok {
+			columns = append([]string{"id"}, columns...)
+		}
+	}
+	return columns
+}
+
+// cell renders a field value: many2one pairs show their display name and
+// Odoo's false empty value is left blank.
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		if !v {
+			return ""
+		}
+		return "true"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		if len(v) == 2 {
+			if name, ok := v[1].(string); ok {
+				return name
+			}
+		}
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
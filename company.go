@@ -0,0 +1,176 @@
+package odoorpc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Company is a `res.company` the user can work with.
+type Company struct {
+	ID   int64
+	Name string
+}
+
+// Companies returns the current company of the authenticated user and the
+// companies the user is allowed to switch to (`res.users.company_ids`).
+func (c *RpcClient) Companies(ctx context.Context) (current Company, allowed []Company, err error) {
+	users, err := c.Read(ctx, "res.users", []int64{c.uid}, Options{Fields: []string{"company_id", "company_ids"}})
+	if err != nil {
+		return Company{}, nil, err
+	}
+	if len(users) == 0 {
+		return Company{}, nil, fmt.Errorf("user %d not found", c.uid)
+	}
+	user := users[0]
+	current = Company{ID: many2oneID(user["company_id"]), Name: many2oneName(user["company_id"])}
+
+	ids := int64List(user["company_ids"])
+	companies, err := c.Read(ctx, "res.company", ids, Options{Fields: []string{"name"}})
+	if err != nil {
+		return Company{}, nil, err
+	}
+	for _, rec := range companies {
+		company := Company{Name: stringValue(rec["name"])}
+		if id, ok := rec["id"].(float64); ok {
+			company.ID = int64(id)
+		}
+		allowed = append(allowed, company)
+	}
+	return current, allowed, nil
+}
+
+// AllowedCompanyIDs returns the companies ORM calls of this client run in:
+// the client's allowed_company_ids context when set, otherwise all the
+// companies of the user.
+func (c *RpcClient) AllowedCompanyIDs(ctx context.Context) ([]int64, error) {
+	if ids := int64List(c.context["allowed_company_ids"]); len(ids) > 0 {
+		return ids, nil
+	}
+	_, allowed, err := c.Companies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(allowed))
+	for i, company := range allowed {
+		ids[i] = company.ID
+	}
+	return ids, nil
+}
+
+// SwitchCompanies returns a client restricted to the given companies, the
+// first one being the active company. Unlike WithCompanies it checks that
+// the user is allowed in each of them and returns a *MultiCompanyError
+// otherwise.
+func (c *RpcClient) SwitchCompanies(ctx context.Context, ids ...int64) (*RpcClient, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("odoorpc: at least one company is required")
+	}
+	_, allowed, err := c.Companies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var denied []int64
+	for _, id := range ids {
+		if !slices.ContainsFunc(allowed, func(company Company) bool { return company.ID == id }) {
+			denied = append(denied, id)
+		}
+	}
+	if len(denied) > 0 {
+		return nil, &MultiCompanyError{Companies: denied}
+	}
+	return c.WithCompanies(ids...), nil
+}
+
+// CheckCompanies verifies that the records of model belong to one of the
+// client's allowed companies (see AllowedCompanyIDs) before writing to them.
+// Records without a company are shared and always pass, as are models
+// without a company_id field. It returns a *MultiCompanyError listing the
+// offending records.
+func (c *RpcClient) CheckCompanies(ctx context.Context, model string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	fields, err := c.FieldsGet(ctx, model, []string{"company_id"}, Options{})
+	if err != nil {
+		return err
+	}
+	if _, ok := fields["company_id"]; !ok {
+		return nil
+	}
+	current, companies, err := c.Companies(ctx)
+	if err != nil {
+		return err
+	}
+	all := []int64{current.ID}
+	for _, company := range companies {
+		if company.ID != current.ID {
+			all = append(all, company.ID)
+		}
+	}
+	allowed := int64List(c.context["allowed_company_ids"])
+	if len(allowed) == 0 {
+		allowed = all
+	}
+	// Read in all the companies of the user, as without
+	// allowed_company_ids Odoo only shows the current one, so that records
+	// of the other companies are judged against allowed below.
+	recs, err := c.WithCompanies(all...).Read(ctx, model, ids, Options{Fields: []string{"company_id"}})
+	if IsAccessError(err) {
+		// Company record rules hide records of companies the user is not in.
+		return &MultiCompanyError{Model: model, IDs: ids, Err: err}
+	}
+	if err != nil {
+		return err
+	}
+	mcErr := &MultiCompanyError{Model: model}
+	for _, rec := range recs {
+		company := many2oneID(rec["company_id"])
+		if company == 0 || slices.Contains(allowed, company) {
+			continue
+		}
+		if id, ok := rec["id"].(float64); ok {
+			mcErr.IDs = append(mcErr.IDs, int64(id))
+		}
+		if !slices.Contains(mcErr.Companies, company) {
+			mcErr.Companies = append(mcErr.Companies, company)
+		}
+	}
+	if len(mcErr.IDs) > 0 {
+		return mcErr
+	}
+	return nil
+}
+
+// int64List converts a JSON decoded list of ids.
+func int64List(v any) []int64 {
+	var ids []int64
+	switch list := v.(type) {
+	case []any:
+		for _, item := range list {
+			switch id := item.(type) {
+			case float64:
+				ids = append(ids, int64(id))
+			case int64:
+				ids = append(ids, id)
+			}
+		}
+	case []int64:
+		ids = append(ids, list...)
+	}
+	return ids
+}
+
+func stringValue(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// many2oneName extracts the display name of a many2one value.
+func many2oneName(v any) string {
+	pair, ok := v.([]any)
+	if !ok || len(pair) < 2 {
+		return ""
+	}
+	return stringValue(pair[1])
+}
@@ -0,0 +1,99 @@
+package odoorpc_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func newCompanyServer(t *testing.T) *fakeOdoo {
+	return newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Method == "login":
+			return 2, nil
+		case call.Model == "res.users" && call.Method == "read":
+			return []map[string]any{{"id": 2, "company_id": []any{1, "Main"}, "company_ids": []any{1, 3}}}, nil
+		case call.Model == "res.company" && call.Method == "read":
+			return []map[string]any{{"id": 1, "name": "Main"}, {"id": 3, "name": "Branch"}}, nil
+		case call.Method == "fields_get":
+			return map[string]any{"company_id": map[string]any{"type": "many2one"}}, nil
+		case call.Model == "account.move" && call.Method == "read":
+			// Like Odoo, only the allowed companies are visible.
+			companies, _ := call.Kwargs["context"].(map[string]any)["allowed_company_ids"].([]any)
+			if !reflect.DeepEqual(companies, []any{float64(1), float64(3)}) {
+				return nil, &fakeError{Name: "odoo.exceptions.AccessError", Message: "record rules"}
+			}
+			return []map[string]any{
+				{"id": 10, "company_id": []any{1, "Main"}},
+				{"id": 11, "company_id": []any{4, "Other"}},
+				{"id": 12, "company_id": false},
+			}, nil
+		case call.Model == "account.move" && call.Method == "write" && call.Kwargs["context"] != nil:
+			return nil, &fakeError{
+				Name:    "odoo.exceptions.UserError",
+				Message: "Empresas incompatibles en los registros:\n- 'INV/1' pertenece a la empresa 'Main'",
+				Debug:   "Traceback (most recent call last):\n  File \"/odoo/models.py\", line 3546, in _check_company\n    raise UserError(_(\"Incompatible companies on records:\") + ...)\n",
+			}
+		case call.Model == "account.move" && call.Method == "write":
+			return nil, &fakeError{Name: "odoo.exceptions.UserError", Message: "Incompatible companies on records:\n- 'INV/1' belongs to company 'Main'"}
+		}
+		return nil, &fakeError{Message: "unexpected " + call.Model + "." + call.Method}
+	})
+}
+
+func TestCompaniesAndSwitch(t *testing.T) {
+	srv := newCompanyServer(t)
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	c.Authenticate(ctx, "admin", "admin", "odoo")
+
+	current, allowed, err := c.Companies(ctx)
+	if err != nil || current.ID != 1 || len(allowed) != 2 || allowed[1].Name != "Branch" {
+		t.Fatalf("Companies: %+v %+v %v", current, allowed, err)
+	}
+	switched, err := c.SwitchCompanies(ctx, 3, 1)
+	if err != nil {
+		t.Fatalf("SwitchCompanies: %v", err)
+	}
+	if !reflect.DeepEqual(switched.Context()["allowed_company_ids"], []any{int64(3), int64(1)}) {
+		t.Fatalf("unexpected context: %v", switched.Context())
+	}
+	var mcErr *odoorpc.MultiCompanyError
+	if _, err := c.SwitchCompanies(ctx, 4); !errors.As(err, &mcErr) || mcErr.Companies[0] != 4 {
+		t.Fatalf("expected MultiCompanyError, got %v", err)
+	}
+}
+
+func TestCheckCompaniesAndServerErrors(t *testing.T) {
+	srv := newCompanyServer(t)
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	c.Authenticate(ctx, "admin", "admin", "odoo")
+
+	err := c.CheckCompanies(ctx, "account.move", []int64{10, 11, 12})
+	var mcErr *odoorpc.MultiCompanyError
+	if !errors.As(err, &mcErr) || !reflect.DeepEqual(mcErr.IDs, []int64{11}) || !reflect.DeepEqual(mcErr.Companies, []int64{4}) {
+		t.Fatalf("expected MultiCompanyError for record 11, got %v", err)
+	}
+
+	// Records of the user's other companies are readable, but outside a
+	// client restricted to the current company.
+	err = c.WithCompanies(1).CheckCompanies(ctx, "account.move", []int64{10, 11, 12})
+	if !errors.As(err, &mcErr) || mcErr.Err != nil || !reflect.DeepEqual(mcErr.IDs, []int64{11}) {
+		t.Fatalf("expected MultiCompanyError for record 11 only, got %v", err)
+	}
+
+	_, err = c.Update(ctx, "account.move", []int64{10}, map[string]any{"partner_id": 5})
+	if !errors.As(err, &mcErr) || !odoorpc.IsUserError(err) {
+		t.Fatalf("expected server MultiCompanyError, got %T %v", err, err)
+	}
+
+	// Translated messages are recognized from the traceback.
+	_, err = c.WithLang("es_ES").Update(ctx, "account.move", []int64{10}, map[string]any{"partner_id": 5})
+	if !errors.As(err, &mcErr) {
+		t.Fatalf("expected MultiCompanyError for translated message, got %T %v", err, err)
+	}
+}
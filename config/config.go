@@ -0,0 +1,264 @@
+// Package config loads Odoo connection profiles.
+//
+// Profiles are read from a TOML file, by default
+// $XDG_CONFIG_HOME/odoorpc/profiles.toml (see os.UserConfigDir):
+//
+//	default = "prod"
+//
+//	[prod]
+//	url = "https://erp.example.com"
+//	db = "prod"
+//	user = "integrations@example.com"
+//	password_command = "pass show odoo/prod"
+//
+//	[local]
+//	url = "http://127.0.0.1:8069"
+//	db = "odoo"
+//	user = "admin"
+//	password_file = "~/.config/odoorpc/local.secret"
+//
+// The ODOO_URL, ODOO_DB, ODOO_USER, ODOO_PASSWORD and ODOO_API_KEY
+// environment variables override the selected profile, ODOO_PROFILE selects
+// it and ODOO_CONFIG points to another file.
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// Environment variables read by Load.
+const (
+	EnvConfig   = "ODOO_CONFIG"
+	EnvProfile  = "ODOO_PROFILE"
+	EnvURL      = "ODOO_URL"
+	EnvDB       = "ODOO_DB"
+	EnvUser     = "ODOO_USER"
+	EnvPassword = "ODOO_PASSWORD"
+	EnvAPIKey   = "ODOO_API_KEY"
+)
+
+// Profile holds the settings needed to connect to an Odoo database.
+type Profile struct {
+	Name string
+	URL  string
+	DB   string
+	User string
+	// Password or API key given inline. APIKey takes precedence.
+	Password string
+	APIKey   string
+	// PasswordFile is read, and trailing newlines trimmed, when no inline
+	// secret is set. A leading "~/" is expanded to the home directory.
+	PasswordFile string
+	// PasswordCommand is run through the shell when no other secret is
+	// set; its trimmed standard output is the password.
+	PasswordCommand string
+}
+
+// File is a parsed profiles file.
+type File struct {
+	// Default is the profile used when none is requested.
+	Default  string
+	Profiles map[string]Profile
+}
+
+// DefaultPath returns the profiles file location, honoring ODOO_CONFIG.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "odoorpc", "profiles.toml"), nil
+}
+
+// LoadFile parses the profiles file at path.
+func LoadFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tables, err := parseTOML(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	file := &File{Default: tables[""]["default"], Profiles: map[string]Profile{}}
+	for name, values := range tables {
+		if name == "" {
+			continue
+		}
+		p := Profile{Name: name}
+		for key, value := range values {
+			switch key {
+			case "url":
+				p.URL = value
+			case "db":
+				p.DB = value
+			case "user":
+				p.User = value
+			case "password":
+				p.Password = value
+			case "api_key":
+				p.APIKey = value
+			case "password_file":
+				p.PasswordFile = value
+			case "password_command":
+				p.PasswordCommand = value
+			default:
+				return nil, fmt.Errorf("%s: profile %q: unknown key %q", path, name, key)
+			}
+		}
+		file.Profiles[name] = p
+	}
+	return file, nil
+}
+
+// Profile returns the named profile, or the default one when name is empty.
+func (f *File) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = f.Default
+	}
+	if name == "" {
+		name = "default"
+	}
+	p, ok := f.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found", name)
+	}
+	return p, nil
+}
+
+// Load returns the named profile from the default profiles file with the
+// environment overrides applied. When name is empty ODOO_PROFILE, then the
+// file default, is used; a missing file or profile is only an error when a
+// profile was explicitly requested, so environment-only setups work.
+func Load(name string) (Profile, error) {
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+	explicit := name != ""
+
+	var p Profile
+	path, err := DefaultPath()
+	if err != nil {
+		return Profile{}, err
+	}
+	file, err := LoadFile(path)
+	switch {
+	case err == nil:
+		p, err = file.Profile(name)
+		if err != nil && explicit {
+			return Profile{}, err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if explicit {
+			return Profile{}, fmt.Errorf("profile %q: %w", name, err)
+		}
+	default:
+		return Profile{}, err
+	}
+	p.ApplyEnv()
+	return p, nil
+}
+
+// ApplyEnv overrides the profile with the ODOO_* environment variables.
+func (p *Profile) ApplyEnv() {
+	override := func(dst *string, key string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	override(&p.URL, EnvURL)
+	override(&p.DB, EnvDB)
+	override(&p.User, EnvUser)
+	// A secret from the environment replaces every secret of the file, so
+	// that ODOO_PASSWORD is not shadowed by an api_key of the profile.
+	password, apiKey := os.Getenv(EnvPassword), os.Getenv(EnvAPIKey)
+	if password != "" || apiKey != "" {
+		p.Password, p.APIKey = password, apiKey
+		p.PasswordFile, p.PasswordCommand = "", ""
+	}
+}
+
+// Credentials returns a provider for the profile secret. Password files are
+// re-read on every call and command output is cached until invalidated, so
+// secrets rotated behind the profile are picked up by long-lived clients.
+func (p Profile) Credentials() (odoorpc.CredentialProvider, error) {
+	switch {
+	case p.APIKey != "":
+		return odoorpc.StaticCredentials(p.APIKey), nil
+	case p.Password != "":
+		return odoorpc.StaticCredentials(p.Password), nil
+	case p.PasswordFile != "":
+		return odoorpc.FileCredentials(expandHome(p.PasswordFile)), nil
+	case p.PasswordCommand != "":
+		command := p.PasswordCommand
+		return odoorpc.NewRotatingCredentials(func(ctx context.Context) (string, time.Time, error) {
+			var stdout, stderr bytes.Buffer
+			cmd := shellCommand(ctx, command)
+			cmd.Stdout, cmd.Stderr = &stdout, &stderr
+			if err := cmd.Run(); err != nil {
+				return "", time.Time{}, fmt.Errorf("password command: %w: %s", err, strings.TrimSpace(stderr.String()))
+			}
+			return strings.TrimRight(stdout.String(), "\r\n"), time.Time{}, nil
+		}, 0), nil
+	}
+	return nil, errors.New("no password configured")
+}
+
+// Secret resolves the password or API key of the profile.
+func (p Profile) Secret(ctx context.Context) (string, error) {
+	creds, err := p.Credentials()
+	if err != nil {
+		return "", err
+	}
+	return creds.Secret(ctx)
+}
+
+// Connect creates a client for the profile and authenticates it.
+func (p Profile) Connect(ctx context.Context, httpClient *http.Client) (*odoorpc.RpcClient, error) {
+	if p.URL == "" || p.DB == "" || p.User == "" {
+		return nil, fmt.Errorf("profile %q is incomplete: url, db and user are required", p.Name)
+	}
+	creds, err := p.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	c := odoorpc.New(p.URL, httpClient)
+	if _, err := c.AuthenticateWith(ctx, p.User, p.DB, creds); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
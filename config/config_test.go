@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testProfiles = `
+# connection profiles
+default = "prod"
+
+[prod]
+url = "https://erp.example.com" # production
+db = "prod"
+user = "bot@example.com"
+password_command = "echo s3cret"
+
+[local]
+url = 'http://127.0.0.1:8069'
+db = "odoo"
+user = "admin"
+password_file = "local.secret"
+`
+
+func writeProfiles(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles.toml")
+	if err := os.WriteFile(path, []byte(testProfiles), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "local.secret"), []byte("admin\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path
+}
+
+func TestLoadDefaultProfileWithEnv(t *testing.T) {
+	t.Setenv(EnvConfig, writeProfiles(t))
+	t.Setenv(EnvDB, "staging")
+
+	p, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if p.Name != "prod" || p.URL != "https://erp.example.com" || p.DB != "staging" {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+	secret, err := p.Secret(context.Background())
+	if err != nil || secret != "s3cret" {
+		t.Fatalf("Secret: %q %v", secret, err)
+	}
+}
+
+func TestLoadPasswordFile(t *testing.T) {
+	path := writeProfiles(t)
+	t.Setenv(EnvConfig, path)
+	t.Chdir(filepath.Dir(path))
+
+	p, err := Load("local")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if secret, err := p.Secret(context.Background()); err != nil || secret != "admin" {
+		t.Fatalf("Secret: %q %v", secret, err)
+	}
+	t.Setenv(EnvAPIKey, "key")
+	p, _ = Load("local")
+	if secret, _ := p.Secret(context.Background()); secret != "key" {
+		t.Fatalf("expected API key override, got %q", secret)
+	}
+	if _, err := Load("missing"); err == nil {
+		t.Fatalf("expected missing profile error")
+	}
+}
+
+func TestEnvPasswordOverridesFileAPIKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles.toml")
+	profiles := "[prod]\nurl = \"https://erp.example.com\"\napi_key = \"file-key\"\n"
+	if err := os.WriteFile(path, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	t.Setenv(EnvConfig, path)
+	t.Setenv(EnvPassword, "env-password")
+
+	p, err := Load("prod")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if secret, err := p.Secret(context.Background()); err != nil || secret != "env-password" {
+		t.Fatalf("expected ODOO_PASSWORD to win, got %q %v", secret, err)
+	}
+}
+
+func TestLoadWithoutFileUsesEnv(t *testing.T) {
+	t.Setenv(EnvConfig, filepath.Join(t.TempDir(), "none.toml"))
+	t.Setenv(EnvURL, "http://odoo:8069")
+	p, err := Load("")
+	if err != nil || p.URL != "http://odoo:8069" {
+		t.Fatalf("Load: %+v %v", p, err)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, src := range []string{"[prod\n", "url\n", "url = \"open\n", "[a]\n[a]\n", "port = 12ab\n"} {
+		if _, err := parseTOML(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
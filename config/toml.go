@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseTOML reads the subset of TOML used by profile files: top level
+// keys, [table] headers and string, boolean or integer values. Values are
+// returned as strings keyed by table name; top level keys use "".
+func parseTOML(r io.Reader) (map[string]map[string]string, error) {
+	tables := map[string]map[string]string{"": {}}
+	current := ""
+	sc := bufio.NewScanner(r)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 || strings.TrimSpace(stripComment(line[end+1:])) != "" {
+				return nil, fmt.Errorf("line %d: invalid table header", lineNo)
+			}
+			current = strings.TrimSpace(line[1:end])
+			if unquoted, err := strconv.Unquote(current); err == nil {
+				current = unquoted
+			}
+			if current == "" {
+				return nil, fmt.Errorf("line %d: empty table name", lineNo)
+			}
+			if _, ok := tables[current]; ok {
+				return nil, fmt.Errorf("line %d: duplicate table %q", lineNo, current)
+			}
+			tables[current] = map[string]string{}
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		tables[current][key] = value
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+func parseTOMLValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if strings.TrimSpace(stripComment(raw[end+1:])) != "" {
+			return "", fmt.Errorf("unexpected text after string")
+		}
+		return strconv.Unquote(raw[:end+1])
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if strings.TrimSpace(stripComment(raw[end+2:])) != "" {
+			return "", fmt.Errorf("unexpected text after string")
+		}
+		return raw[1 : end+1], nil
+	default:
+		value := strings.TrimSpace(stripComment(raw))
+		if value == "true" || value == "false" {
+			return value, nil
+		}
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value, nil
+		}
+		return "", fmt.Errorf("unsupported value %q", value)
+	}
+}
+
+// closingQuote returns the index of the quote ending the basic string at
+// the start of s, skipping escaped quotes.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func stripComment(s string) string {
+	if i := strings.IndexByte(s, '#'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
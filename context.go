@@ -0,0 +1,61 @@
+package odoorpc
+
+import "maps"
+
+// WithContext returns a client sharing the connection and credentials of c
+// whose ORM calls run with values merged into its default context, like
+// Odoo's `env.with_context`. Keys in values override those of c, and the
+// context given per call through Options.Context overrides both.
+//
+// Common keys are "lang", "tz", "active_test", "tracking_disable" and
+// "allowed_company_ids". c itself is not modified.
+func (c *RpcClient) WithContext(values map[string]any) *RpcClient {
+	derived := *c
+	derived.context = make(map[string]any, len(c.context)+len(values))
+	maps.Copy(derived.context, c.context)
+	maps.Copy(derived.context, values)
+	return &derived
+}
+
+// WithLang returns a client reading and writing translatable fields in
+// lang, e.g. "es_ES".
+func (c *RpcClient) WithLang(lang string) *RpcClient {
+	return c.WithContext(map[string]any{"lang": lang})
+}
+
+// WithTimezone returns a client using tz, e.g. "Europe/Madrid", for
+// timezone dependent computations such as date domains and reports.
+func (c *RpcClient) WithTimezone(tz string) *RpcClient {
+	return c.WithContext(map[string]any{"tz": tz})
+}
+
+// WithCompanies returns a client restricted to the given companies. The
+// first id is the active company, used as default for new records.
+func (c *RpcClient) WithCompanies(ids ...int64) *RpcClient {
+	companies := make([]any, len(ids))
+	for i, id := range ids {
+		companies[i] = id
+	}
+	return c.WithContext(map[string]any{"allowed_company_ids": companies})
+}
+
+// Context returns a copy of the default context of the client.
+func (c *RpcClient) Context() map[string]any {
+	return maps.Clone(c.context)
+}
+
+// mergeContext returns kwargs with the client context merged under the
+// per call context. kwargs is not modified.
+func (c *RpcClient) mergeContext(kwargs map[string]any) map[string]any {
+	if len(c.context) == 0 {
+		return kwargs
+	}
+	merged := make(map[string]any, len(kwargs)+1)
+	maps.Copy(merged, kwargs)
+	values := maps.Clone(c.context)
+	if callContext, ok := kwargs["context"].(map[string]any); ok {
+		maps.Copy(values, callContext)
+	}
+	merged["context"] = values
+	return merged
+}
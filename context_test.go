@@ -0,0 +1,49 @@
+package odoorpc_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestWithContextMergesIntoEveryCall(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "login":
+			return 2, nil
+		case "create":
+			return 1, nil
+		case "write", "unlink":
+			return true, nil
+		}
+		return []any{}, nil
+	})
+	base := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := base.Authenticate(ctx, "admin", "admin", "odoo"); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	c := base.WithLang("es_ES").WithCompanies(3, 1).WithContext(map[string]any{"tracking_disable": true})
+
+	c.Create(ctx, "res.partner", map[string]any{"name": "A"})
+	c.Update(ctx, "res.partner", []int64{1}, map[string]any{"name": "B"})
+	c.Unlink(ctx, "res.partner", []int64{1})
+	c.SearchRead(ctx, "res.partner", nil, odoorpc.Options{Context: map[string]any{"lang": "fr_FR"}})
+	base.Search(ctx, "res.partner", nil, odoorpc.Options{})
+
+	want := map[string]any{"lang": "es_ES", "allowed_company_ids": []any{float64(3), float64(1)}, "tracking_disable": true}
+	calls := srv.Calls[1:]
+	for _, call := range calls[:3] {
+		if !reflect.DeepEqual(call.Kwargs["context"], want) {
+			t.Errorf("%s: unexpected context %v", call.Method, call.Kwargs["context"])
+		}
+	}
+	if lang := calls[3].Kwargs["context"].(map[string]any)["lang"]; lang != "fr_FR" {
+		t.Errorf("per call context should win, got lang %v", lang)
+	}
+	if _, ok := calls[4].Kwargs["context"]; ok {
+		t.Errorf("parent client must not inherit derived context: %v", calls[4].Kwargs)
+	}
+}
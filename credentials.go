@@ -0,0 +1,114 @@
+package odoorpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the password or API key sent with each call.
+//
+// The client asks the provider whenever it needs the secret instead of
+// storing it, so implementations decide how long a secret lives in memory
+// and can rotate it at any time.
+type CredentialProvider interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// CredentialFunc adapts a function to the CredentialProvider interface.
+type CredentialFunc func(ctx context.Context) (string, error)
+
+// Secret calls f.
+func (f CredentialFunc) Secret(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+type staticCredentials string
+
+func (s staticCredentials) Secret(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// StaticCredentials returns a provider for a fixed secret.
+func StaticCredentials(secret string) CredentialProvider {
+	return staticCredentials(secret)
+}
+
+// EnvCredentials returns a provider reading the named environment variable
+// on every call.
+func EnvCredentials(name string) CredentialProvider {
+	return CredentialFunc(func(context.Context) (string, error) {
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	})
+}
+
+// FileCredentials returns a provider reading the secret from path on every
+// call, trimming trailing newlines. It suits secrets mounted by an
+// orchestrator and replaced in place on rotation.
+func FileCredentials(path string) CredentialProvider {
+	return CredentialFunc(func(context.Context) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		secret := strings.TrimRight(string(data), "\r\n")
+		if secret == "" {
+			return "", fmt.Errorf("credentials file %s is empty", path)
+		}
+		return secret, nil
+	})
+}
+
+// RotatingCredentials caches a secret obtained from a fetch function, for
+// example an API key issued by a vault, until it expires or is invalidated.
+// It is safe for concurrent use.
+type RotatingCredentials struct {
+	fetch func(ctx context.Context) (secret string, expires time.Time, err error)
+	// skew renews the secret this long before it expires.
+	skew time.Duration
+
+	mu      sync.Mutex
+	secret  string
+	expires time.Time
+}
+
+// NewRotatingCredentials returns a provider calling fetch when no secret is
+// cached or the cached one expires within skew. A zero expiry time means the
+// secret is kept until Invalidate is called.
+func NewRotatingCredentials(fetch func(ctx context.Context) (string, time.Time, error), skew time.Duration) *RotatingCredentials {
+	return &RotatingCredentials{fetch: fetch, skew: skew}
+}
+
+// Secret returns the cached secret, fetching a new one when needed.
+func (r *RotatingCredentials) Secret(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.secret != "" && (r.expires.IsZero() || time.Now().Add(r.skew).Before(r.expires)) {
+		return r.secret, nil
+	}
+	secret, expires, err := r.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", errors.New("credentials fetch returned an empty secret")
+	}
+	r.secret, r.expires = secret, expires
+	return secret, nil
+}
+
+// Invalidate drops the cached secret so that the next call fetches a new
+// one, e.g. after the server rejected it.
+func (r *RotatingCredentials) Invalidate() {
+	r.mu.Lock()
+	r.secret, r.expires = "", time.Time{}
+	r.mu.Unlock()
+}
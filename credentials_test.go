@@ -0,0 +1,74 @@
+package odoorpc_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestAuthenticateWithAsksProviderPerCall(t *testing.T) {
+	var passwords []any
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		if call.Service == "common" {
+			passwords = append(passwords, call.Args[2])
+			return 2, nil
+		}
+		return []int{}, nil
+	})
+	path := filepath.Join(t.TempDir(), "secret")
+	os.WriteFile(path, []byte("first\n"), 0o600)
+
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.AuthenticateWith(ctx, "admin", "odoo", odoorpc.FileCredentials(path)); err != nil {
+		t.Fatalf("AuthenticateWith: %v", err)
+	}
+	os.WriteFile(path, []byte("second\n"), 0o600)
+	if _, err := c.Search(ctx, "res.partner", nil, odoorpc.Options{}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	last := srv.Calls[len(srv.Calls)-1]
+	if passwords[0] != "first" {
+		t.Fatalf("unexpected login password %v", passwords[0])
+	}
+	if last.Method != "search" || last.Password != "second" {
+		t.Fatalf("unexpected last call %+v", last)
+	}
+
+	os.Remove(path)
+	if _, err := c.Search(ctx, "res.partner", nil, odoorpc.Options{}); err == nil {
+		t.Fatalf("expected credentials error")
+	}
+}
+
+func TestRotatingCredentials(t *testing.T) {
+	fetches := 0
+	now := time.Now()
+	r := odoorpc.NewRotatingCredentials(func(context.Context) (string, time.Time, error) {
+		fetches++
+		if fetches == 1 {
+			return "expiring", now.Add(time.Second), nil
+		}
+		return "fresh", now.Add(time.Hour), nil
+	}, time.Minute)
+	ctx := context.Background()
+	if s, _ := r.Secret(ctx); s != "expiring" {
+		t.Fatalf("unexpected secret %q", s)
+	}
+	// Expires within the skew, so it is renewed.
+	if s, _ := r.Secret(ctx); s != "fresh" {
+		t.Fatalf("expected renewed secret, got %q", s)
+	}
+	if s, _ := r.Secret(ctx); s != "fresh" || fetches != 2 {
+		t.Fatalf("expected cached secret, got %q after %d fetches", s, fetches)
+	}
+	r.Invalidate()
+	r.Secret(ctx)
+	if fetches != 3 {
+		t.Fatalf("expected fetch after Invalidate, got %d", fetches)
+	}
+}
@@ -0,0 +1,263 @@
+package odoorpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// BackupFormat selects the payload produced by BackupDatabase.
+type BackupFormat string
+
+const (
+	// BackupZip is a zip archive with the SQL dump and the filestore.
+	BackupZip BackupFormat = "zip"
+	// BackupDump is a pg_dump custom format file without the filestore.
+	BackupDump BackupFormat = "dump"
+)
+
+// CreateDatabaseOptions holds the parameters of CreateDatabase.
+type CreateDatabaseOptions struct {
+	// Name of the new database.
+	Name string
+	// Lang is the default language, e.g. "en_US".
+	Lang string
+	// CountryCode optionally sets the main company country, e.g. "es".
+	CountryCode string
+	// Phone optionally sets the main company phone.
+	Phone string
+	// Demo loads demonstration data.
+	Demo bool
+	// Login of the administrator user, "admin" when empty.
+	Login string
+	// Password of the administrator user.
+	Password string
+}
+
+// call performs a JSON-RPC `call` on the given service.
+func (c *RpcClient) call(ctx context.Context, service, method string, args []any, result any) error {
+	if args == nil {
+		args = []any{}
+	}
+	params := map[string]any{
+		"service": service,
+		"method":  method,
+		"args":    args,
+	}
+	return c.rpc.Call(ctx, "call", params, result)
+}
+
+// ListDatabases returns the databases available on the server.
+//
+// It fails when the server runs with `list_db = False`.
+func (c *RpcClient) ListDatabases(ctx context.Context) ([]string, error) {
+	var res []string
+	if err := c.call(ctx, "db", "list", nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DatabaseExists reports whether the named database exists.
+func (c *RpcClient) DatabaseExists(ctx context.Context, name string) (bool, error) {
+	var res bool
+	if err := c.call(ctx, "db", "db_exist", []any{name}, &res); err != nil {
+		return false, err
+	}
+	return res, nil
+}
+
+// CreateDatabase creates and initializes a new database.
+func (c *RpcClient) CreateDatabase(ctx context.Context, masterPassword string, opts CreateDatabaseOptions) error {
+	if opts.Name == "" {
+		return errors.New("odoorpc: database name is required")
+	}
+	login := opts.Login
+	if login == "" {
+		login = "admin"
+	}
+	lang := opts.Lang
+	if lang == "" {
+		lang = "en_US"
+	}
+	var country, phone any
+	if opts.CountryCode != "" {
+		country = opts.CountryCode
+	}
+	if opts.Phone != "" {
+		phone = opts.Phone
+	}
+	args := []any{masterPassword, opts.Name, opts.Demo, lang, opts.Password, login, country, phone}
+	var res bool
+	if err := c.call(ctx, "db", "create_database", args, &res); err != nil {
+		return err
+	}
+	if !res {
+		return fmt.Errorf("odoorpc: database %q was not created", opts.Name)
+	}
+	return nil
+}
+
+// DuplicateDatabase copies source into a new database named target.
+func (c *RpcClient) DuplicateDatabase(ctx context.Context, masterPassword, source, target string) error {
+	var res bool
+	if err := c.call(ctx, "db", "duplicate_database", []any{masterPassword, source, target}, &res); err != nil {
+		return err
+	}
+	if !res {
+		return fmt.Errorf("odoorpc: database %q was not duplicated", source)
+	}
+	return nil
+}
+
+// RenameDatabase renames the database oldName to newName.
+func (c *RpcClient) RenameDatabase(ctx context.Context, masterPassword, oldName, newName string) error {
+	var res bool
+	if err := c.call(ctx, "db", "rename", []any{masterPassword, oldName, newName}, &res); err != nil {
+		return err
+	}
+	if !res {
+		return fmt.Errorf("odoorpc: database %q was not renamed", oldName)
+	}
+	return nil
+}
+
+// DropDatabase deletes the named database and its filestore.
+func (c *RpcClient) DropDatabase(ctx context.Context, masterPassword, name string) error {
+	var res bool
+	if err := c.call(ctx, "db", "drop", []any{masterPassword, name}, &res); err != nil {
+		return err
+	}
+	if !res {
+		return fmt.Errorf("odoorpc: database %q was not dropped", name)
+	}
+	return nil
+}
+
+// ChangeMasterPassword replaces the server master password.
+func (c *RpcClient) ChangeMasterPassword(ctx context.Context, masterPassword, newPassword string) error {
+	var res bool
+	if err := c.call(ctx, "db", "change_admin_password", []any{masterPassword, newPassword}, &res); err != nil {
+		return err
+	}
+	if !res {
+		return errors.New("odoorpc: master password was not changed")
+	}
+	return nil
+}
+
+// BackupDatabase streams a backup of the named database to w using the
+// `/web/database/backup` route. The payload is never fully held in memory.
+func (c *RpcClient) BackupDatabase(ctx context.Context, masterPassword, name string, format BackupFormat, w io.Writer) error {
+	if format == "" {
+		format = BackupZip
+	}
+	form := url.Values{
+		"master_pwd":    {masterPassword},
+		"name":          {name},
+		"backup_format": {string(format)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.rpc.BaseURL()+"/web/database/backup", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create backup request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.rpc.HTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("backup request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// On failure Odoo renders the database manager page with the error.
+	if resp.StatusCode != http.StatusOK || strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return databaseManagerError("backup", resp)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to stream backup: %w", err)
+	}
+	return nil
+}
+
+// RestoreDatabase creates the database name from a backup read from r using
+// the `/web/database/restore` route. When asCopy is true Odoo assigns a new
+// database UUID, as it should for duplicated tenants.
+func (c *RpcClient) RestoreDatabase(ctx context.Context, masterPassword, name string, r io.Reader, asCopy bool) error {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		err := writeRestoreForm(mw, masterPassword, name, r, asCopy)
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.rpc.BaseURL()+"/web/database/restore", pr)
+	if err != nil {
+		pr.Close()
+		return fmt.Errorf("failed to create restore request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	// A successful restore redirects to the manager; do not follow it so
+	// that the error page can be told apart.
+	httpClient := *c.rpc.HTTPClient()
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("restore request error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		return nil
+	}
+	return databaseManagerError("restore", resp)
+}
+
+func writeRestoreForm(mw *multipart.Writer, masterPassword, name string, r io.Reader, asCopy bool) error {
+	fields := [][2]string{
+		{"master_pwd", masterPassword},
+		{"name", name},
+		{"copy", fmt.Sprint(asCopy)},
+	}
+	for _, f := range fields {
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			return err
+		}
+	}
+	part, err := mw.CreateFormFile("backup_file", name+".zip")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, r)
+	return err
+}
+
+var managerErrorRe = regexp.MustCompile(`(?s)class="alert alert-danger"[^>]*>(.*?)</div>`)
+
+// databaseManagerError extracts the error message rendered by Odoo's
+// database manager page.
+func databaseManagerError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if m := managerErrorRe.FindSubmatch(body); m != nil {
+		msg := strings.TrimSpace(html.UnescapeString(stripTags(string(m[1]))))
+		return fmt.Errorf("database %s failed: %s", op, msg)
+	}
+	return fmt.Errorf("database %s failed with status %d", op, resp.StatusCode)
+}
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+func stripTags(s string) string {
+	return tagRe.ReplaceAllString(s, "")
+}
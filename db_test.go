@@ -0,0 +1,125 @@
+package odoorpc_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/jsonrpc"
+)
+
+func TestDatabaseService(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		if call.Service != "db" {
+			t.Errorf("unexpected service %q", call.Service)
+			return nil, &fakeError{Message: "unexpected service"}
+		}
+		switch call.Method {
+		case "list":
+			return []string{"odoo", "tenant"}, nil
+		case "db_exist":
+			return call.Args[0] == "tenant", nil
+		case "create_database":
+			if call.Args[1] != "new" || call.Args[5] != "admin" || call.Args[3] != "es_ES" {
+				t.Errorf("unexpected create args: %v", call.Args)
+			}
+			return true, nil
+		case "drop":
+			return false, nil
+		}
+		return nil, &fakeError{Message: "unknown method " + call.Method}
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+
+	dbs, err := c.ListDatabases(ctx)
+	if err != nil || len(dbs) != 2 {
+		t.Fatalf("ListDatabases: %v %v", dbs, err)
+	}
+	if ok, err := c.DatabaseExists(ctx, "tenant"); err != nil || !ok {
+		t.Fatalf("DatabaseExists: %v %v", ok, err)
+	}
+	opts := odoorpc.CreateDatabaseOptions{Name: "new", Lang: "es_ES", Password: "pw"}
+	if err := c.CreateDatabase(ctx, "master", opts); err != nil {
+		t.Fatalf("CreateDatabase: %v", err)
+	}
+	if err := c.DropDatabase(ctx, "master", "missing"); err == nil {
+		t.Fatalf("expected DropDatabase error")
+	}
+}
+
+func TestBackupRestoreDatabase(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) { return nil, nil })
+	srv.Mux.HandleFunc("/web/database/backup", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("master_pwd") != "master" {
+			w.Header().Set("Content-Type", "text/html")
+			io.WriteString(w, `<div class="alert alert-danger" role="alert">Access Denied</div>`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		io.WriteString(w, "PK-backup-"+r.FormValue("name"))
+	})
+	srv.Mux.HandleFunc("/web/database/restore", func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("backup_file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		if string(data) != "PK-backup-odoo" || r.FormValue("copy") != "true" {
+			t.Errorf("unexpected restore payload %q", data)
+			http.Error(w, "unexpected payload", http.StatusBadRequest)
+			return
+		}
+		http.Redirect(w, r, "/web/database/manager", http.StatusSeeOther)
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := c.BackupDatabase(ctx, "master", "odoo", odoorpc.BackupZip, &buf); err != nil {
+		t.Fatalf("BackupDatabase: %v", err)
+	}
+	err := c.BackupDatabase(ctx, "wrong", "odoo", odoorpc.BackupZip, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "Access Denied") {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+	if err := c.RestoreDatabase(ctx, "master", "copy", &buf, true); err != nil {
+		t.Fatalf("RestoreDatabase: %v", err)
+	}
+}
+
+func TestDatabaseCallsAreScrubbedInCassettes(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) { return true, nil })
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := jsonrpc.NewRecorder(path, jsonrpc.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := odoorpc.New(srv.URL, rec.Client())
+	ctx := context.Background()
+	opts := odoorpc.CreateDatabaseOptions{Name: "new", Password: "admin-s3cret"}
+	if err := c.CreateDatabase(ctx, "master-s3cret", opts); err != nil {
+		t.Fatalf("CreateDatabase: %v", err)
+	}
+	if err := c.ChangeMasterPassword(ctx, "master-s3cret", "new-master-s3cret"); err != nil {
+		t.Fatalf("ChangeMasterPassword: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("cassette leaks a password:\n%s", data)
+	}
+}
@@ -102,6 +102,15 @@ func (d Domain) In(field string, values []int64) Domain {
 	return append(d, []any{field, "in", vals})
 }
 
+// InStrings appends an "in" condition for a slice of string values.
+func (d Domain) InStrings(field string, values []string) Domain {
+	vals := make([]any, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return append(d, []any{field, "in", vals})
+}
+
 // ChildOf appends a "child_of" condition.
 func (d Domain) ChildOf(field string, value any) Domain {
 	return append(d, []any{field, "child_of", value})
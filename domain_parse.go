@@ -0,0 +1,186 @@
+package odoorpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseDomain parses a domain written in Odoo's Python literal syntax, as
+// found in XML views, filters and the developer tools:
+//
+//	[('is_company', '=', True), '|', ('name', 'ilike', 'acme'), ('ref', '!=', False)]
+//
+// Lists and tuples become []any, integers int64, floats float64, strings
+// string, True/False bool and None nil. JSON syntax (true/false/null and
+// double quoted strings) is accepted as well.
+func ParseDomain(s string) (Domain, error) {
+	p := &literalParser{src: s}
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected trailing input")
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("domain must be a list, got %T", v)
+	}
+	for i, item := range items {
+		switch item := item.(type) {
+		case string:
+			if !isLogicalOperator(item) {
+				return nil, fmt.Errorf("domain item %d: unknown operator %q", i, item)
+			}
+		case []any:
+			if len(item) != 3 {
+				return nil, fmt.Errorf("domain item %d: condition must have 3 elements, got %d", i, len(item))
+			}
+		default:
+			return nil, fmt.Errorf("domain item %d: unexpected %T", i, item)
+		}
+	}
+	return Domain(items), nil
+}
+
+type literalParser struct {
+	src string
+	pos int
+}
+
+func (p *literalParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("domain syntax error at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *literalParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *literalParser) parseValue() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch ch := p.src[p.pos]; {
+	case ch == '[':
+		return p.parseSequence(']')
+	case ch == '(':
+		return p.parseSequence(')')
+	case ch == '\'' || ch == '"':
+		return p.parseString(ch)
+	case ch == '-' || ch == '+' || ch == '.' || (ch >= '0' && ch <= '9'):
+		return p.parseNumber()
+	default:
+		return p.parseIdent()
+	}
+}
+
+func (p *literalParser) parseSequence(end byte) (any, error) {
+	p.pos++ // opening bracket
+	items := []any{}
+	for {
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == end {
+			p.pos++
+			return items, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("missing %q", end)
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case end:
+		default:
+			return nil, p.errorf("expected ',' or %q", end)
+		}
+	}
+}
+
+func (p *literalParser) parseString(quote byte) (any, error) {
+	p.pos++ // opening quote
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+		switch {
+		case ch == quote:
+			p.pos++
+			return b.String(), nil
+		case ch == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch esc := p.src[p.pos]; esc {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'u':
+				if p.pos+4 >= len(p.src) {
+					return nil, p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.src[p.pos+1:p.pos+5], 16, 32)
+				if err != nil {
+					return nil, p.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				p.pos += 4
+			default:
+				b.WriteByte(esc)
+			}
+			p.pos++
+		default:
+			b.WriteByte(ch)
+			p.pos++
+		}
+	}
+	return nil, p.errorf("unterminated string")
+}
+
+func (p *literalParser) parseNumber() (any, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("+-.0123456789eE_", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	text := strings.ReplaceAll(p.src[start:p.pos], "_", "")
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number %q", text)
+	}
+	return f, nil
+}
+
+func (p *literalParser) parseIdent() (any, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || p.src[p.pos] == '_') {
+		p.pos++
+	}
+	switch ident := p.src[start:p.pos]; ident {
+	case "True", "true":
+		return true, nil
+	case "False", "false":
+		return false, nil
+	case "None", "null":
+		return nil, nil
+	case "":
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	default:
+		p.pos = start
+		return nil, p.errorf("unknown identifier %q", ident)
+	}
+}
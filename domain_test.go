@@ -71,3 +71,35 @@ func TestDomainAndIgnoresEmpty(t *testing.T) {
 		t.Fatalf("unexpected domain when left empty: %#v", got)
 	}
 }
+
+func TestParseDomain(t *testing.T) {
+	got, err := odoorpc.ParseDomain(`['|', ('name', 'ilike', "O'Neil"), ("is_company", "=", True), ['id', 'in', (1, 2)], ('ref', '!=', False), ('amount', '>=', -1.5)]`)
+	if err != nil {
+		t.Fatalf("ParseDomain: %v", err)
+	}
+	want := odoorpc.Domain{
+		"|",
+		[]any{"name", "ilike", "O'Neil"},
+		[]any{"is_company", "=", true},
+		[]any{"id", "in", []any{int64(1), int64(2)}},
+		[]any{"ref", "!=", false},
+		[]any{"amount", ">=", -1.5},
+	}
+	if !reflect.DeepEqual([]any(got), []any(want)) {
+		t.Fatalf("unexpected domain: %#v", got)
+	}
+}
+
+func TestParseDomainErrors(t *testing.T) {
+	for _, src := range []string{
+		`('name', '=', 'x')`,
+		`[('name', '=')]`,
+		`[('name', '=', 'x')`,
+		`['^']`,
+		`[('name', '=', undefined)]`,
+	} {
+		if _, err := odoorpc.ParseDomain(src); err == nil {
+			t.Errorf("expected error for %s", src)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package odoorpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Guadalsistema/odoorpc/jsonrpc"
+)
+
+// Exception classes reported in jsonrpc.ErrorData.Name.
+const (
+	ExceptionAccessError     = "odoo.exceptions.AccessError"
+	ExceptionAccessDenied    = "odoo.exceptions.AccessDenied"
+	ExceptionMissingError    = "odoo.exceptions.MissingError"
+	ExceptionUserError       = "odoo.exceptions.UserError"
+	ExceptionValidationError = "odoo.exceptions.ValidationError"
+	ExceptionRedirectWarning = "odoo.exceptions.RedirectWarning"
+)
+
+// OdooError returns the server side exception wrapped in err, if any.
+func OdooError(err error) (*jsonrpc.Error, bool) {
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr, true
+	}
+	return nil, false
+}
+
+func hasException(err error, name string) bool {
+	rpcErr, ok := OdooError(err)
+	return ok && rpcErr.Data.Name == name
+}
+
+// IsAccessError reports whether err is an Odoo AccessError: the user lacks
+// the access rights or record rules to perform the operation.
+func IsAccessError(err error) bool { return hasException(err, ExceptionAccessError) }
+
+// IsAccessDenied reports whether err is an Odoo AccessDenied, raised for
+// invalid credentials.
+func IsAccessDenied(err error) bool { return hasException(err, ExceptionAccessDenied) }
+
+// IsMissingError reports whether err is an Odoo MissingError: a record was
+// deleted or never existed.
+func IsMissingError(err error) bool { return hasException(err, ExceptionMissingError) }
+
+// IsValidationError reports whether err is an Odoo ValidationError raised
+// by a constraint.
+func IsValidationError(err error) bool { return hasException(err, ExceptionValidationError) }
+
+// IsUserError reports whether err is an Odoo UserError or one of its
+// subclasses meant to be shown to the user.
+func IsUserError(err error) bool {
+	return hasException(err, ExceptionUserError) || IsValidationError(err) || hasException(err, ExceptionRedirectWarning)
+}
+
+// MultiCompanyError is returned when an operation involves companies the
+// user, or the client's allowed_company_ids, does not give access to.
+type MultiCompanyError struct {
+	// Model and IDs identify the offending records, when known.
+	Model string
+	IDs   []int64
+	// Companies lists the offending company ids, when known.
+	Companies []int64
+	// Err is the server error, nil for failures detected client side.
+	Err error
+}
+
+func (e *MultiCompanyError) Error() string {
+	if e.Err != nil {
+		return "multi-company access error: " + e.Err.Error()
+	}
+	var b strings.Builder
+	b.WriteString("multi-company access error")
+	if e.Model != "" {
+		fmt.Fprintf(&b, ": %s records %v", e.Model, e.IDs)
+	}
+	if len(e.Companies) > 0 {
+		fmt.Fprintf(&b, " belong to companies %v outside the allowed ones", e.Companies)
+	}
+	return b.String()
+}
+
+func (e *MultiCompanyError) Unwrap() error { return e.Err }
+
+// Markers of the exceptions Odoo raises for company inconsistencies. The
+// message is translated in the language of the call, so the markers are
+// also looked up in the traceback, which quotes the raising source line
+// and with it the untranslated message.
+var multiCompanyMarkers = []struct {
+	exception string
+	text      string
+}{
+	{ExceptionAccessError, "unauthorized or invalid companies"},
+	{ExceptionUserError, "Incompatible companies on records"},
+	{ExceptionUserError, "in _check_company"},
+}
+
+// classifyError wraps server errors about companies in a MultiCompanyError.
+func classifyError(model string, err error) error {
+	rpcErr, ok := OdooError(err)
+	if !ok {
+		return err
+	}
+	for _, m := range multiCompanyMarkers {
+		if rpcErr.Data.Name != m.exception {
+			continue
+		}
+		if strings.Contains(rpcErr.Data.Message, m.text) || strings.Contains(rpcErr.Data.Debug, m.text) {
+			return &MultiCompanyError{Model: model, Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package odoorpc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultExportPageSize is the number of records fetched per request when
+// ExportOptions.PageSize is zero.
+const DefaultExportPageSize = 500
+
+// ExportOptions controls bulk exports.
+type ExportOptions struct {
+	// PageSize is the number of records read per request.
+	PageSize int
+	// Order sorts the exported records. When empty records are exported by
+	// ascending id, paging on the id instead of an offset so that concurrent
+	// inserts do not shift pages.
+	Order string
+	// Context is passed to the ORM calls, e.g. to set "lang".
+	Context map[string]any
+}
+
+// ExportData wraps the ORM `export_data` method for the given records.
+//
+// fields are export paths relative to model, using "/" to traverse
+// relations ("partner_id/name", "order_line/product_id/default_code") and
+// "id" or "partner_id/id" for external ids. x2many paths produce one extra
+// row per related record, with the parent columns left empty.
+func (c *RpcClient) ExportData(ctx context.Context, model string, ids []int64, fields []string, opts Options) ([][]any, error) {
+	if len(ids) == 0 {
+		return [][]any{}, nil
+	}
+	idArgs := make([]any, len(ids))
+	for i, id := range ids {
+		idArgs[i] = id
+	}
+	var res struct {
+		Datas [][]any `json:"datas"`
+	}
+	if err := c.executeKw(ctx, model, "export_data", []any{idArgs, fields}, opts.Kwargs(), &res); err != nil {
+		return nil, err
+	}
+	return res.Datas, nil
+}
+
+// Export streams the records of model matching domain to w, one column per
+// entry of fields. It returns the number of records exported.
+//
+// When every field is a plain field of model, records are fetched with
+// paginated search_read and many2one values are written as display names.
+// Paths traversing relations are resolved server side with export_data.
+// The header row and w.Close are handled by Export; w is closed on
+// failure too.
+func (c *RpcClient) Export(ctx context.Context, model string, domain Domain, fields []string, w RowWriter, opts ExportOptions) (int, error) {
+	total, err := c.export(ctx, model, domain, fields, w, opts)
+	if err != nil {
+		w.Close()
+		return total, err
+	}
+	return total, w.Close()
+}
+
+func (c *RpcClient) export(ctx context.Context, model string, domain Domain, fields []string, w RowWriter, opts ExportOptions) (int, error) {
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("odoorpc: export requires at least one field")
+	}
+	if domain == nil {
+		domain = Domain{}
+	}
+	size := opts.PageSize
+	if size <= 0 {
+		size = DefaultExportPageSize
+	}
+	if err := w.WriteHeader(fields); err != nil {
+		return 0, err
+	}
+
+	plain := true
+	for _, f := range fields {
+		if strings.Contains(f, "/") || f == "id" || f == ".id" {
+			plain = false
+			break
+		}
+	}
+
+	total := 0
+	var lastID int64
+	for page := 0; ; page++ {
+		pageDomain := domain
+		pageOpts := Options{Limit: size, Order: opts.Order, Context: opts.Context}
+		if opts.Order == "" {
+			pageOpts.Order = "id"
+			if lastID > 0 {
+				// Appending keeps the domain flat; Odoo ANDs top level terms.
+				pageDomain = append(slices.Clone(domain), []any{"id", ">", lastID})
+			}
+		} else {
+			pageOpts.Offset = page * size
+		}
+
+		var n int
+		var err error
+		if plain {
+			n, lastID, err = c.exportPlainPage(ctx, model, pageDomain, fields, w, pageOpts)
+		} else {
+			n, lastID, err = c.exportDataPage(ctx, model, pageDomain, fields, w, pageOpts)
+		}
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < size {
+			break
+		}
+	}
+	return total, nil
+}
+
+func (c *RpcClient) exportPlainPage(ctx context.Context, model string, domain Domain, fields []string, w RowWriter, opts Options) (int, int64, error) {
+	opts.Fields = fields
+	recs, err := c.SearchRead(ctx, model, domain, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	var lastID int64
+	for _, rec := range recs {
+		row := make([]any, len(fields))
+		for i, f := range fields {
+			v := rec[f]
+			// many2one values are [id, display_name]
+			if pair, ok := v.([]any); ok && len(pair) == 2 {
+				if name, ok := pair[1].(string); ok {
+					v = name
+				}
+			}
+			row[i] = v
+		}
+		if err := w.WriteRow(row); err != nil {
+			return 0, 0, err
+		}
+		if id, ok := rec["id"].(float64); ok {
+			lastID = int64(id)
+		}
+	}
+	return len(recs), lastID, nil
+}
+
+func (c *RpcClient) exportDataPage(ctx context.Context, model string, domain Domain, fields []string, w RowWriter, opts Options) (int, int64, error) {
+	ids, err := c.Search(ctx, model, domain, Options{Limit: opts.Limit, Offset: opts.Offset, Order: opts.Order, Context: opts.Context})
+	if err != nil {
+		return 0, 0, err
+	}
+	rows, err := c.ExportData(ctx, model, ids, fields, Options{Context: opts.Context})
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			return 0, 0, err
+		}
+	}
+	var lastID int64
+	if len(ids) > 0 {
+		lastID = ids[len(ids)-1]
+	}
+	return len(ids), lastID, nil
+}
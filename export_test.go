@@ -0,0 +1,139 @@
+package odoorpc_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestExportPlainFieldsPagesByID(t *testing.T) {
+	records := []map[string]any{
+		{"id": 1, "name": "A", "country_id": []any{68, "Spain"}},
+		{"id": 2, "name": "B", "country_id": false},
+		{"id": 3, "name": "C, Ltd", "country_id": []any{75, "France"}},
+	}
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		if call.Method != "search_read" {
+			return nil, &fakeError{Message: "unexpected " + call.Method}
+		}
+		var after float64
+		for _, item := range call.Args[0].([]any) {
+			if cond, ok := item.([]any); ok && len(cond) == 3 && cond[0] == "id" {
+				after = cond[2].(float64)
+			}
+		}
+		var page []map[string]any
+		for _, rec := range records {
+			if float64(rec["id"].(int)) > after && len(page) < int(call.Kwargs["limit"].(float64)) {
+				page = append(page, rec)
+			}
+		}
+		return page, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+
+	var buf bytes.Buffer
+	n, err := c.Export(context.Background(), "res.partner", nil, []string{"name", "country_id"}, odoorpc.NewCSVWriter(&buf), odoorpc.ExportOptions{PageSize: 2})
+	if err != nil || n != 3 {
+		t.Fatalf("Export: n=%d err=%v", n, err)
+	}
+	want := "name,country_id\nA,Spain\nB,\n\"C, Ltd\",France\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+}
+
+func TestExportRelationalPathsUsesExportData(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "search":
+			return []int{10}, nil
+		case "export_data":
+			return map[string]any{"datas": [][]any{{"SO001", "P-1"}, {"", "P-2"}}}, nil
+		}
+		return nil, &fakeError{Message: "unexpected " + call.Method}
+	})
+	c := odoorpc.New(srv.URL, nil)
+
+	var buf bytes.Buffer
+	fields := []string{"name", "order_line/product_id/default_code"}
+	if _, err := c.Export(context.Background(), "sale.order", nil, fields, odoorpc.NewJSONLWriter(&buf), odoorpc.ExportOptions{}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != `{"name":"SO001","order_line/product_id/default_code":"P-1"}` {
+		t.Fatalf("unexpected jsonl:\n%s", buf.String())
+	}
+}
+
+// closeRecorder is a RowWriter that records whether it was closed.
+type closeRecorder struct {
+	odoorpc.RowWriter
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return c.RowWriter.Close()
+}
+
+func TestExportPagesMultiLeafDomainFlat(t *testing.T) {
+	var domains []any
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		domains = append(domains, call.Args[0])
+		if len(domains) == 1 {
+			return []map[string]any{{"id": 4, "name": "A"}, {"id": 7, "name": "B"}}, nil
+		}
+		return nil, &fakeError{Message: "boom"}
+	})
+	c := odoorpc.New(srv.URL, nil)
+
+	domain := odoorpc.Domain{"|", []any{"is_company", "=", true}, []any{"customer_rank", ">", 0}}
+	w := &closeRecorder{RowWriter: odoorpc.NewCSVWriter(io.Discard)}
+	_, err := c.Export(context.Background(), "res.partner", domain, []string{"name"}, w, odoorpc.ExportOptions{PageSize: 2})
+	if err == nil {
+		t.Fatalf("expected error from second page")
+	}
+	if !w.closed {
+		t.Errorf("writer not closed on error")
+	}
+	want := []any{"|", []any{"is_company", "=", true}, []any{"customer_rank", ">", float64(0)}, []any{"id", ">", float64(7)}}
+	if len(domains) != 2 || !reflect.DeepEqual(domains[1], want) {
+		t.Errorf("unexpected second page domain %#v", domains)
+	}
+	if len(domain) != 3 {
+		t.Errorf("caller domain modified: %v", domain)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := odoorpc.NewXLSXWriter(&buf)
+	w.WriteHeader([]string{"name", "amount"})
+	w.WriteRow([]any{"A & B", 12.5})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		if !strings.Contains(string(data), "A &amp; B") || !strings.Contains(string(data), "<v>12.5</v>") {
+			t.Fatalf("unexpected sheet: %s", data)
+		}
+		return
+	}
+	t.Fatalf("worksheet missing")
+}
@@ -0,0 +1,199 @@
+package odoorpc
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RowWriter receives the rows produced by Export.
+type RowWriter interface {
+	// WriteHeader is called once with the column names before any row.
+	WriteHeader(columns []string) error
+	// WriteRow writes one row with a value per column.
+	WriteRow(row []any) error
+	// Close flushes buffered output. It does not close the underlying writer.
+	Close() error
+}
+
+// formatCell renders a value for text based formats. Odoo uses false for
+// empty values, so false is written as an empty cell.
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		if !v {
+			return ""
+		}
+		return "True"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter returns a RowWriter producing CSV with a header row.
+func NewCSVWriter(w io.Writer) RowWriter {
+	return &csvRowWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvRowWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvRowWriter) WriteRow(row []any) error {
+	record := make([]string, len(row))
+	for i, v := range row {
+		record[i] = formatCell(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlRowWriter struct {
+	w       *bufio.Writer
+	enc     *json.Encoder
+	columns []string
+}
+
+// NewJSONLWriter returns a RowWriter producing one JSON object per line,
+// keyed by column name. Values are written as returned by Odoo.
+func NewJSONLWriter(w io.Writer) RowWriter {
+	bw := bufio.NewWriter(w)
+	return &jsonlRowWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (j *jsonlRowWriter) WriteHeader(columns []string) error {
+	j.columns = columns
+	return nil
+}
+
+func (j *jsonlRowWriter) WriteRow(row []any) error {
+	obj := make(map[string]any, len(j.columns))
+	for i, col := range j.columns {
+		if i < len(row) {
+			obj[col] = row[i]
+		}
+	}
+	return j.enc.Encode(obj)
+}
+
+func (j *jsonlRowWriter) Close() error {
+	return j.w.Flush()
+}
+
+// XLSX parts written before the worksheet. The worksheet is streamed as the
+// last zip entry so rows never have to be held in memory.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+type xlsxRowWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	err   error
+}
+
+// NewXLSXWriter returns a RowWriter producing a single sheet XLSX workbook.
+// Numbers and booleans keep their type; everything else is written as text.
+func NewXLSXWriter(w io.Writer) RowWriter {
+	x := &xlsxRowWriter{zw: zip.NewWriter(w)}
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, p := range parts {
+		f, err := x.zw.Create(p.name)
+		if err == nil {
+			_, err = io.WriteString(f, p.body)
+		}
+		if err != nil {
+			x.err = err
+			return x
+		}
+	}
+	f, err := x.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		x.err = err
+		return x
+	}
+	x.sheet = bufio.NewWriter(f)
+	_, x.err = x.sheet.WriteString(xlsxSheetStart)
+	return x
+}
+
+func (x *xlsxRowWriter) WriteHeader(columns []string) error {
+	row := make([]any, len(columns))
+	for i, c := range columns {
+		row[i] = c
+	}
+	return x.WriteRow(row)
+}
+
+func (x *xlsxRowWriter) WriteRow(row []any) error {
+	if x.err != nil {
+		return x.err
+	}
+	x.sheet.WriteString("<row>")
+	for _, v := range row {
+		switch v := v.(type) {
+		case float64:
+			fmt.Fprintf(x.sheet, `<c t="n"><v>%s</v></c>`, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			// false is Odoo's empty value; only true is a real boolean here.
+			if v {
+				x.sheet.WriteString(`<c t="b"><v>1</v></c>`)
+			} else {
+				x.sheet.WriteString(`<c/>`)
+			}
+		default:
+			x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(x.sheet, []byte(formatCell(v)))
+			x.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, x.err = x.sheet.WriteString("</row>")
+	return x.err
+}
+
+func (x *xlsxRowWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	if _, err := x.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
@@ -0,0 +1,98 @@
+package odoorpc_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeCall is a decoded JSON-RPC call received by fakeOdoo.
+//
+// For `object.execute_kw` calls Model and Method refer to the ORM call and
+// Args/Kwargs to its arguments; for other services Method is the service
+// method and Args its positional arguments.
+type fakeCall struct {
+	Path     string
+	Service  string
+	Password any
+	Model    string
+	Method   string
+	Args     []any
+	Kwargs   map[string]any
+}
+
+// fakeError makes fakeOdoo answer with an Odoo style JSON-RPC error.
+type fakeError struct {
+	Name    string
+	Message string
+	// Debug is the server traceback.
+	Debug string
+}
+
+func (e *fakeError) Error() string { return e.Message }
+
+// fakeOdoo is a minimal in-process Odoo JSON-RPC server for tests.
+type fakeOdoo struct {
+	*httptest.Server
+	Mux   *http.ServeMux
+	Calls []fakeCall
+}
+
+func newFakeOdoo(t *testing.T, handle func(call fakeCall) (any, error)) *fakeOdoo {
+	t.Helper()
+	f := &fakeOdoo{Mux: http.NewServeMux()}
+	f.Mux.HandleFunc("/jsonrpc", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any `json:"id"`
+			Params struct {
+				Service string `json:"service"`
+				Method  string `json:"method"`
+				Args    []any  `json:"args"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		call := fakeCall{Path: r.URL.Path, Service: req.Params.Service, Method: req.Params.Method, Args: req.Params.Args}
+		if call.Service == "object" && call.Method == "execute_kw" && len(call.Args) >= 6 {
+			all := call.Args
+			call.Password = all[2]
+			call.Model, _ = all[3].(string)
+			call.Method, _ = all[4].(string)
+			call.Args, _ = all[5].([]any)
+			if len(all) > 6 {
+				call.Kwargs, _ = all[6].(map[string]any)
+			}
+		}
+		f.Calls = append(f.Calls, call)
+		writeFakeResult(w, req.ID, handle, call)
+	})
+	f.Server = httptest.NewServer(f.Mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func writeFakeResult(w http.ResponseWriter, id any, handle func(call fakeCall) (any, error), call fakeCall) {
+	resp := map[string]any{"jsonrpc": "2.0", "id": id}
+	res, err := handle(call)
+	if err != nil {
+		data := map[string]any{"name": "odoo.exceptions.UserError", "message": err.Error()}
+		if fe, ok := err.(*fakeError); ok {
+			if fe.Name != "" {
+				data["name"] = fe.Name
+			}
+			data["debug"] = fe.Debug
+		}
+		resp["error"] = map[string]any{
+			"code":    200,
+			"message": "Odoo Server Error",
+			"data":    data,
+		}
+	} else {
+		resp["result"] = res
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,397 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects whether a Recorder captures live traffic or serves it back.
+type Mode int
+
+const (
+	// ModeRecord forwards requests to the real server and stores each
+	// request/response pair in the cassette.
+	ModeRecord Mode = iota
+	// ModeReplay serves responses from the cassette without touching the
+	// network. Requests that do not match a stored interaction fail.
+	ModeReplay
+)
+
+// scrubbed replaces credentials before they are written to a cassette.
+const scrubbed = "********"
+
+// Interaction is a single captured request/response pair.
+//
+// Text responses are kept readable in Response; binary ones such as
+// backups, PDFs or attachment downloads are stored in ResponseBytes,
+// which encodes as base64.
+type Interaction struct {
+	Key           Key             `json:"key"`
+	Request       json.RawMessage `json:"request,omitempty"`
+	Status        int             `json:"status"`
+	Header        http.Header     `json:"header,omitempty"`
+	Response      string          `json:"response"`
+	ResponseBytes []byte          `json:"response_bytes,omitempty"`
+}
+
+// body returns the recorded response body.
+func (in Interaction) body() []byte {
+	if in.ResponseBytes != nil {
+		return in.ResponseBytes
+	}
+	return []byte(in.Response)
+}
+
+// Key identifies an interaction for replay matching.
+type Key struct {
+	Path    string `json:"path"`
+	Method  string `json:"method"`
+	Service string `json:"service,omitempty"`
+	Call    string `json:"call,omitempty"`
+	Model   string `json:"model,omitempty"`
+	Args    string `json:"args,omitempty"`
+}
+
+func (k Key) String() string {
+	var parts []string
+	for _, s := range []string{k.Path, k.Method, k.Service, k.Model, k.Call, k.Args} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Cassette is the on-disk fixture format.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records JSON-RPC traffic to a
+// cassette file or replays it back.
+//
+// In replay mode interactions are matched on path, JSON-RPC method, service,
+// model, called method and normalized arguments (request ids and credentials
+// are ignored). Each stored interaction is served at most once and in order
+// for identical keys.
+//
+// Response headers are recorded as sent, including Set-Cookie: treat
+// cassettes recorded against a real server as secrets.
+type Recorder struct {
+	mode Mode
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates a Recorder backed by the cassette at path.
+//
+// In ModeRecord, next is used to reach the real server (http.DefaultTransport
+// when nil) and the cassette is written by Save. In ModeReplay the cassette is
+// loaded from path and next is ignored.
+func NewRecorder(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path, next: next}
+	if r.next == nil {
+		r.next = http.DefaultTransport
+	}
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: failed to read %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette: failed to decode %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Client returns an *http.Client using the recorder as transport, suitable
+// for passing to New.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cassette: failed to read request body: %w", err)
+		}
+	}
+	key := interactionKey(req, body)
+	if r.mode == ModeReplay {
+		return r.replay(req, key, body)
+	}
+	return r.record(req, key, body)
+}
+
+func (r *Recorder) record(req *http.Request, key Key, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read response body: %w", err)
+	}
+
+	// Headers are kept so that Set-Cookie, and with it session flows, can
+	// be replayed. The length is recomputed on replay.
+	header := resp.Header.Clone()
+	header.Del("Content-Length")
+	header.Del("Date")
+	in := Interaction{Key: key, Status: resp.StatusCode, Header: header}
+	if utf8.Valid(respBody) {
+		in.Response = string(respBody)
+	} else {
+		in.ResponseBytes = respBody
+	}
+	if scrubbedBody, ok := scrubRequest(body); ok {
+		in.Request = scrubbedBody
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, key Key, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Key != key {
+			continue
+		}
+		r.used[i] = true
+		respBody := in.body()
+		if id, ok := requestID(body); ok {
+			respBody = withResponseID(respBody, id)
+		}
+		header := in.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette: unexpected call %s", key)
+}
+
+// Save writes the recorded interactions to the cassette file. It is a no-op
+// in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("cassette: failed to encode: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		return fmt.Errorf("cassette: failed to write %s: %w", r.path, err)
+	}
+	return nil
+}
+
+// Unused returns the keys of the interactions that were never replayed.
+// Tests can use it to assert that the code under test issued every
+// expected call.
+func (r *Recorder) Unused() []Key {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var keys []Key
+	for i, in := range r.cassette.Interactions {
+		if !r.used[i] {
+			keys = append(keys, in.Key)
+		}
+	}
+	return keys
+}
+
+// errNotJSONRPC is reported when a request body is not a JSON-RPC envelope.
+var errNotJSONRPC = errors.New("not a json-rpc request")
+
+func decodeRequest(body []byte) (map[string]any, error) {
+	if len(body) == 0 {
+		return nil, errNotJSONRPC
+	}
+	var req map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		return nil, err
+	}
+	if _, ok := req["jsonrpc"]; !ok {
+		return nil, errNotJSONRPC
+	}
+	return req, nil
+}
+
+func interactionKey(req *http.Request, body []byte) Key {
+	key := Key{Path: req.URL.Path, Method: req.Method}
+	rpcReq, err := decodeRequest(body)
+	if err != nil {
+		return key
+	}
+	key.Method, _ = rpcReq["method"].(string)
+	params, _ := rpcReq["params"].(map[string]any)
+	if params == nil {
+		return key
+	}
+	service, _ := params["service"].(string)
+	if service == "" {
+		// Session routes (/web/...) carry named params directly.
+		key.Model, _ = params["model"].(string)
+		key.Call, _ = params["method"].(string)
+		key.Args = canonical(scrubParams(params))
+		return key
+	}
+	key.Service = service
+	key.Call, _ = params["method"].(string)
+	args, _ := params["args"].([]any)
+	args = scrubArgs(service, key.Call, args)
+	if service == "object" && len(args) >= 5 {
+		// [db, uid, password, model, method, args...]
+		key.Model, _ = args[3].(string)
+		key.Call, _ = args[4].(string)
+		key.Args = canonical(args[5:])
+		return key
+	}
+	key.Args = canonical(args)
+	return key
+}
+
+// dbPasswords lists the positions of the passwords taken by db service
+// methods besides the master password, which comes first.
+var dbPasswords = map[string][]int{
+	"create_database":       {4}, // admin user password
+	"change_admin_password": {1}, // new master password
+}
+
+// dbPublic lists the db service methods taking no master password.
+var dbPublic = []string{"list", "db_exist", "server_version", "list_lang", "list_countries"}
+
+// scrubArgs hides the positional passwords of common, object and db
+// service calls.
+func scrubArgs(service, method string, args []any) []any {
+	out := append([]any(nil), args...)
+	switch {
+	case service == "object" && len(out) > 2:
+		out[2] = scrubbed
+	case service == "common" && (method == "login" || method == "authenticate") && len(out) > 2:
+		out[2] = scrubbed
+	case service == "db" && !slices.Contains(dbPublic, method):
+		for _, i := range append([]int{0}, dbPasswords[method]...) {
+			if i < len(out) {
+				out[i] = scrubbed
+			}
+		}
+	}
+	return out
+}
+
+func scrubParams(params map[string]any) map[string]any {
+	out := make(map[string]any, len(params))
+	for k, v := range params {
+		switch k {
+		case "password", "master_pwd", "api_key":
+			out[k] = scrubbed
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func scrubRequest(body []byte) (json.RawMessage, bool) {
+	rpcReq, err := decodeRequest(body)
+	if err != nil {
+		return nil, false
+	}
+	delete(rpcReq, "id")
+	if params, ok := rpcReq["params"].(map[string]any); ok {
+		if service, _ := params["service"].(string); service != "" {
+			method, _ := params["method"].(string)
+			args, _ := params["args"].([]any)
+			params["args"] = scrubArgs(service, method, args)
+		} else {
+			rpcReq["params"] = scrubParams(params)
+		}
+	}
+	data, err := json.Marshal(rpcReq)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// canonical serializes v with sorted map keys so equal arguments always
+// produce the same string.
+func canonical(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func requestID(body []byte) (json.RawMessage, bool) {
+	var env struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(body, &env); err != nil || len(env.ID) == 0 {
+		return nil, false
+	}
+	return env.ID, true
+}
+
+func withResponseID(body []byte, id json.RawMessage) []byte {
+	var env map[string]json.RawMessage
+	if err := json.Unmarshal(body, &env); err != nil {
+		return body
+	}
+	if _, ok := env["jsonrpc"]; !ok {
+		return body
+	}
+	env["id"] = id
+	data, err := json.Marshal(env)
+	if err != nil {
+		return body
+	}
+	return data
+}
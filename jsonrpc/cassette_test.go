@@ -0,0 +1,146 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+			return
+		}
+		resp := map[string]any{"jsonrpc": "2.0", "id": req["id"], "result": []int{1, 2, 3}}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	params := map[string]any{
+		"service": "object",
+		"method":  "execute_kw",
+		"args":    []any{"odoo", 2, "secret", "res.partner", "search", []any{[]any{}}, map[string]any{"limit": 3}},
+	}
+
+	rec, err := NewRecorder(path, ModeRecord, srv.Client().Transport)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	var ids []int64
+	if err := New(srv.URL, rec.Client()).Call(context.Background(), "call", params, &ids); err != nil {
+		t.Fatalf("record Call: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Fatalf("cassette leaks password: %s", data)
+	}
+	srv.Close()
+
+	rep, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder replay: %v", err)
+	}
+	c := New(srv.URL, rep.Client())
+	// A different password must still match the recorded interaction.
+	params["args"].([]any)[2] = "other"
+	ids = nil
+	if err := c.Call(context.Background(), "call", params, &ids); err != nil {
+		t.Fatalf("replay Call: %v", err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("unexpected replayed result: %v", ids)
+	}
+	if unused := rep.Unused(); len(unused) != 0 {
+		t.Fatalf("unexpected unused interactions: %v", unused)
+	}
+	if err := c.Call(context.Background(), "call", params, &ids); err == nil {
+		t.Fatalf("expected error for call beyond cassette")
+	}
+}
+
+func TestRecorderReplayUnexpectedArgs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := Cassette{Interactions: []Interaction{{
+		Key:      Key{Path: "/jsonrpc", Method: "call", Service: "object", Model: "res.partner", Call: "search", Args: `[[[]]]`},
+		Status:   http.StatusOK,
+		Response: `{"jsonrpc":"2.0","id":1,"result":[]}`,
+	}}}
+	data, _ := json.Marshal(cassette)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	rep, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	params := map[string]any{
+		"service": "object",
+		"method":  "execute_kw",
+		"args":    []any{"odoo", 2, "pw", "res.partner", "search", []any{[]any{[]any{"id", "=", 1}}}},
+	}
+	err = New("http://odoo.invalid", rep.Client()).Call(context.Background(), "call", params, nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpected call") {
+		t.Fatalf("expected unexpected call error, got %v", err)
+	}
+}
+
+func TestRecorderReplaysBinaryBodiesAndCookies(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n\xe2\xe3\xcf\xd3\x00\xff")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "abc", Path: "/"})
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(pdf)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := NewRecorder(path, ModeRecord, srv.Client().Transport)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	resp, err := rec.Client().Get(srv.URL + "/report/pdf/sale.report_saleorder/1")
+	if err != nil {
+		t.Fatalf("record Get: %v", err)
+	}
+	resp.Body.Close()
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	srv.Close()
+
+	rep, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder replay: %v", err)
+	}
+	resp, err = rep.Client().Get(srv.URL + "/report/pdf/sale.report_saleorder/1")
+	if err != nil {
+		t.Fatalf("replay Get: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !bytes.Equal(body, pdf) {
+		t.Errorf("binary body corrupted: %q", body)
+	}
+	if cookies := resp.Cookies(); len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Errorf("Set-Cookie not replayed: %v", cookies)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
@@ -1,7 +1,6 @@
 package jsonrpc
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -37,6 +36,17 @@ func New(endpoint string, httpClient *http.Client) *NetClient {
 	return &NetClient{endpoint: endpoint, httpClient: httpClient}
 }
 
+// BaseURL returns the server root URL, without the /jsonrpc suffix.
+func (c *NetClient) BaseURL() string {
+	return strings.TrimSuffix(c.endpoint, "/jsonrpc")
+}
+
+// HTTPClient returns the underlying HTTP client, sharing the session cookie
+// jar used for JSON-RPC calls.
+func (c *NetClient) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 type request struct {
 	JSONRPC string `json:"jsonrpc"`
 	Method  string `json:"method"`
@@ -44,35 +54,71 @@ type request struct {
 	ID      uint64 `json:"id"`
 }
 
-type rpcError struct {
-	Code    int             `json:"code"`
-	Message string          `json:"message"`
-	Data    json.RawMessage `json:"data"`
+// Error is a JSON-RPC error returned by the server. Odoo describes the
+// Python exception in Data.
+type Error struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    ErrorData `json:"data"`
+}
+
+// ErrorData carries the details of an Odoo exception.
+type ErrorData struct {
+	// Name is the qualified exception class, e.g.
+	// "odoo.exceptions.AccessError".
+	Name string `json:"name"`
+	// Message is the user facing exception message.
+	Message   string `json:"message"`
+	Debug     string `json:"debug"`
+	Arguments []any  `json:"arguments"`
+	// ExceptionType is the short exception kind used by the web client,
+	// e.g. "access_error" or "validation_error".
+	ExceptionType string `json:"exception_type"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+	if e.Data.Message != "" {
+		msg += ": " + e.Data.Message
+	}
+	return msg
 }
 
 type response struct {
 	JSONRPC string          `json:"jsonrpc"`
 	Result  json.RawMessage `json:"result"`
-	Error   *rpcError       `json:"error"`
+	Error   *Error          `json:"error"`
 	ID      uint64          `json:"id"`
 }
 
 // Call performs a JSON-RPC request and decodes the result into result.
 func (c *NetClient) Call(ctx context.Context, method string, params any, result any) error {
+	return c.call(ctx, c.endpoint, method, params, result)
+}
+
+// CallPath performs a JSON-RPC request against another route of the same
+// server, such as the session routes under /web, sharing the cookie jar.
+func (c *NetClient) CallPath(ctx context.Context, path string, method string, params any, result any) error {
+	return c.call(ctx, c.BaseURL()+path, method, params, result)
+}
+
+func (c *NetClient) call(ctx context.Context, endpoint string, method string, params any, result any) error {
 	id := atomic.AddUint64(&c.nextID, 1)
 	reqBody, err := json.Marshal(request{JSONRPC: "2.0", Method: method, Params: params, ID: id})
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(reqBody))
+	reqReader, length := requestBody(reqBody, params)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, reqReader)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP request for %q: %w", method, err)
 	}
+	httpReq.ContentLength = length
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("HTTP request error to %s: %w", c.endpoint, err)
+		return fmt.Errorf("HTTP request error to %s: %w", endpoint, err)
 	}
 	defer resp.Body.Close()
 
@@ -97,7 +143,7 @@ func (c *NetClient) Call(ctx context.Context, method string, params any, result
 
 	// Check for JSON-RPC errors
 	if rpcResp.Error != nil {
-		return fmt.Errorf("jsonrpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+		return rpcResp.Error
 	}
 
 	// Unmarshal the result
@@ -1,8 +1,11 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -90,3 +93,106 @@ func TestCallInvalidJSON(t *testing.T) {
 		t.Fatalf("expected decode error")
 	}
 }
+
+func TestCallRPCErrorData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"error": map[string]any{
+				"code":    200,
+				"message": "Odoo Server Error",
+				"data": map[string]any{
+					"name":           "odoo.exceptions.AccessError",
+					"message":        "You are not allowed to access this document.",
+					"exception_type": "access_error",
+				},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+	c := New(srv.URL, srv.Client())
+	err := c.Call(context.Background(), "m", nil, nil)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected *Error, got %T %v", err, err)
+	}
+	if rpcErr.Data.Name != "odoo.exceptions.AccessError" || rpcErr.Data.ExceptionType != "access_error" {
+		t.Fatalf("unexpected error data: %+v", rpcErr.Data)
+	}
+}
+
+func TestBase64ValueIgnoresLookalikeStrings(t *testing.T) {
+	var got []any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any   `json:"id"`
+			Params []any `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+			return
+		}
+		got = req.Params
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": true})
+	}))
+	defer srv.Close()
+
+	lookalike := "\x00b64:0123456789abcdef"
+	params := []any{lookalike, NewBase64Value(strings.NewReader("hello"), 5)}
+	if err := New(srv.URL, nil).Call(context.Background(), "call", params, nil); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if len(got) != 2 || got[0] != lookalike || got[1] != "aGVsbG8=" {
+		t.Errorf("server received %q", got)
+	}
+}
+
+func TestCallBase64FindsKeyAfterLargeValues(t *testing.T) {
+	padding := strings.Repeat("x", 2<<20)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("missing") {
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": [{"description": %q}]}`, padding)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": 1, "result": [{"description": %q, "datas": "aGVsbG8="}]}`, padding)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, nil)
+	var buf bytes.Buffer
+	n, err := c.CallBase64(context.Background(), "/jsonrpc", "call", nil, "datas", &buf)
+	if err != nil || n != 5 || buf.String() != "hello" {
+		t.Fatalf("CallBase64 = %d, %v, %q", n, err, buf.String())
+	}
+	_, err = c.CallBase64(context.Background(), "/jsonrpc?missing", "call", nil, "datas", &buf)
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected an oversize error, got %v", err)
+	}
+}
+
+func TestBase64ValueNestedInParams(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any            `json:"id"`
+			Params map[string]any `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.Params
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": true})
+	}))
+	defer srv.Close()
+
+	params := map[string]any{"args": []any{struct {
+		Datas *Base64Value `json:"datas"`
+	}{NewBase64Value(strings.NewReader("hello"), 5)}}}
+	if err := New(srv.URL, nil).Call(context.Background(), "call", params, nil); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	args, _ := got["args"].([]any)
+	if len(args) != 1 || args[0].(map[string]any)["datas"] != "aGVsbG8=" {
+		t.Errorf("server received %v", got)
+	}
+}
@@ -0,0 +1,322 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+)
+
+// ErrNoValue is returned by CallBase64 when the result has no value for
+// the key, or its value is false.
+var ErrNoValue = errors.New("jsonrpc: no value in result")
+
+// streamMarker prefixes the placeholder strings written by Base64Value.
+// A user string may produce the same bytes, so placeholders are only
+// replaced when followed by the random token of a value of the call.
+const streamMarker = `"\u0000b64:`
+
+// maxResultHead bounds the part of a CallBase64 response kept to decode
+// errors.
+const maxResultHead = 1 << 20
+
+// Base64Value is a call parameter sent as the base64 encoding of a reader,
+// streamed into the request body instead of being built in memory. It can
+// be used once.
+type Base64Value struct {
+	r     io.Reader
+	size  int64
+	token string
+}
+
+// NewBase64Value returns a parameter encoding r. size is the number of
+// bytes r yields, used to announce the request length; pass -1 when
+// unknown to send the request chunked.
+func NewBase64Value(r io.Reader, size int64) *Base64Value {
+	var b [16]byte
+	rand.Read(b[:])
+	return &Base64Value{r: r, size: size, token: hex.EncodeToString(b[:])}
+}
+
+// MarshalJSON writes a placeholder replaced by the data when the request
+// is sent.
+func (v *Base64Value) MarshalJSON() ([]byte, error) {
+	return []byte(streamMarker + v.token + `"`), nil
+}
+
+// collectStreams returns the Base64Value parameters found in params by
+// token. params was marshaled already, so it holds no cycles.
+func collectStreams(params any) map[string]*Base64Value {
+	found := map[string]*Base64Value{}
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer:
+			if v.IsNil() {
+				return
+			}
+			if bv, ok := v.Interface().(*Base64Value); ok {
+				found[bv.token] = bv
+				return
+			}
+			walk(v.Elem())
+		case reflect.Map:
+			for iter := v.MapRange(); iter.Next(); {
+				walk(iter.Value())
+			}
+		case reflect.Slice, reflect.Array:
+			for i := range v.Len() {
+				walk(v.Index(i))
+			}
+		case reflect.Struct:
+			for i := range v.NumField() {
+				if v.Type().Field(i).IsExported() {
+					walk(v.Field(i))
+				}
+			}
+		}
+	}
+	walk(reflect.ValueOf(params))
+	return found
+}
+
+// encodedLen returns the length of the base64 string, or -1.
+func (v *Base64Value) encodedLen() int64 {
+	if v.size < 0 {
+		return -1
+	}
+	return int64(base64.StdEncoding.EncodedLen(int(v.size)))
+}
+
+func (v *Base64Value) writeTo(pw *io.PipeWriter) {
+	enc := base64.NewEncoder(base64.StdEncoding, pw)
+	_, err := io.Copy(enc, v.r)
+	if err == nil {
+		err = enc.Close()
+	}
+	pw.CloseWithError(err)
+}
+
+// requestBody returns the request body marshaled from params, splicing
+// the data of their Base64Value parameters, and its length or -1.
+func requestBody(body []byte, params any) (io.Reader, int64) {
+	if !bytes.Contains(body, []byte(streamMarker)) {
+		return bytes.NewReader(body), int64(len(body))
+	}
+	streams := collectStreams(params)
+	var parts []io.Reader
+	var pipes []*io.PipeReader
+	length := int64(0)
+	for from := 0; ; {
+		i := bytes.Index(body[from:], []byte(streamMarker))
+		if i < 0 {
+			break
+		}
+		i += from
+		j := bytes.IndexByte(body[i+len(streamMarker):], '"')
+		if j < 0 {
+			break
+		}
+		end := i + len(streamMarker) + j
+		token := string(body[i+len(streamMarker) : end])
+		v := streams[token]
+		// A value is sent once, even if given twice.
+		delete(streams, token)
+		if v == nil {
+			// A user string looking like a placeholder.
+			from = end
+			continue
+		}
+		parts = append(parts, bytes.NewReader(body[:i+1]))
+		pr, pw := io.Pipe()
+		go v.writeTo(pw)
+		parts = append(parts, pr)
+		pipes = append(pipes, pr)
+		if n := v.encodedLen(); n < 0 || length < 0 {
+			length = -1
+		} else {
+			length += int64(i+1) + n
+		}
+		body = body[end:] // keep the closing quote
+		from = 0
+	}
+	parts = append(parts, bytes.NewReader(body))
+	if length >= 0 {
+		length += int64(len(body))
+	}
+	return &splicedBody{Reader: io.MultiReader(parts...), pipes: pipes}, length
+}
+
+// splicedBody closes the pipes when the transport is done with the body,
+// so the encoding goroutines end even if the request failed early.
+type splicedBody struct {
+	io.Reader
+	pipes []*io.PipeReader
+}
+
+func (b *splicedBody) Close() error {
+	for _, pr := range b.pipes {
+		pr.Close()
+	}
+	return nil
+}
+
+// CallBase64 performs a JSON-RPC request against path and streams the
+// decoded base64 string found under key in the result to w, without
+// holding the response in memory. It is meant for results with a single
+// binary value, such as reading the "datas" field of one attachment.
+func (c *NetClient) CallBase64(ctx context.Context, path, method string, params any, key string, w io.Writer) (int64, error) {
+	id := atomic.AddUint64(&c.nextID, 1)
+	reqBody, err := json.Marshal(request{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	endpoint := c.BaseURL() + path
+	body, length := requestBody(reqBody, params)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create HTTP request for %q: %w", method, err)
+	}
+	httpReq.ContentLength = length
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("HTTP request error to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return 0, fmt.Errorf("jsonrpc error response status: %d body: %s", resp.StatusCode, snippet)
+	}
+
+	br := bufio.NewReader(resp.Body)
+	// head keeps the start of the response to decode errors, while the key
+	// is matched in a window holding it and the byte before.
+	var head bytes.Buffer
+	truncated := false
+	pattern := []byte(`"` + key + `"`)
+	window := make([]byte, 0, len(pattern)+1)
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if head.Len() < maxResultHead {
+			head.WriteByte(b)
+		} else {
+			truncated = true
+		}
+		if len(window) == cap(window) {
+			copy(window, window[1:])
+			window = window[:len(window)-1]
+		}
+		window = append(window, b)
+		if b != '"' || !bytes.HasSuffix(window, pattern) {
+			continue
+		}
+		if len(window) > len(pattern) && window[0] == '\\' {
+			continue
+		}
+		first, err := skipToValue(br)
+		if err != nil {
+			return 0, err
+		}
+		if first != '"' {
+			return 0, ErrNoValue
+		}
+		n, err := io.Copy(w, base64.NewDecoder(base64.StdEncoding, &stringReader{r: br}))
+		if err != nil {
+			return n, fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		io.Copy(io.Discard, br)
+		return n, nil
+	}
+
+	if truncated {
+		return 0, fmt.Errorf("jsonrpc: result of %q has no %q value and exceeds %d bytes", method, key, maxResultHead)
+	}
+	var rpcResp response
+	if err := json.Unmarshal(head.Bytes(), &rpcResp); err != nil {
+		return 0, fmt.Errorf("jsonrpc decode failed: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return 0, rpcResp.Error
+	}
+	return 0, ErrNoValue
+}
+
+// skipToValue consumes the colon after a key and returns the first byte of
+// the value.
+func skipToValue(br *bufio.Reader) (byte, error) {
+	colon := false
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read response body: %w", err)
+		}
+		switch {
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r':
+		case b == ':' && !colon:
+			colon = true
+		case colon:
+			return b, nil
+		default:
+			return 0, fmt.Errorf("jsonrpc: unexpected %q after key", b)
+		}
+	}
+}
+
+// stringReader reads the content of a JSON string up to its closing quote.
+// Base64 needs no escapes; escaped slashes are accepted.
+type stringReader struct {
+	r    *bufio.Reader
+	done bool
+}
+
+func (s *stringReader) Read(p []byte) (int, error) {
+	if s.done {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+		switch b {
+		case '"':
+			s.done = true
+			if n == 0 {
+				return 0, io.EOF
+			}
+			return n, nil
+		case '\\':
+			esc, err := s.r.ReadByte()
+			if err != nil {
+				return n, io.ErrUnexpectedEOF
+			}
+			if esc != '/' {
+				return n, fmt.Errorf("unexpected escape \\%c in base64 string", esc)
+			}
+			b = '/'
+		}
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,212 @@
+package odoorpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultLoadChunkSize is the number of rows sent per load call when
+// LoadOptions.ChunkSize is zero.
+const DefaultLoadChunkSize = 1000
+
+// LoadOptions controls bulk imports.
+type LoadOptions struct {
+	// ChunkSize is the maximum number of rows per request. Chunks are only
+	// split before rows whose first column is not empty, so one2many
+	// continuation rows stay with their parent record.
+	ChunkSize int
+	// DryRun validates the rows with Odoo's import test mode and rolls
+	// back, so no record is created.
+	DryRun bool
+	// StopOnError stops after the first chunk that reports errors.
+	StopOnError bool
+	// Context is passed to the ORM call.
+	Context map[string]any
+}
+
+// LoadMessage is a message reported by Odoo for an imported row.
+type LoadMessage struct {
+	// Type is "error", "warning" or "info".
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	// Field is the technical name of the offending field, if any.
+	Field string `json:"field"`
+	// Row is the zero-based index of the offending row in the input, or -1
+	// when the message does not refer to a row.
+	Row int `json:"-"`
+	// Record is the index of the record within its chunk as sent by Odoo.
+	Record *int `json:"record"`
+	Rows   *struct {
+		From int `json:"from"`
+		To   int `json:"to"`
+	} `json:"rows"`
+}
+
+// LoadResult is the outcome of a bulk import.
+type LoadResult struct {
+	// IDs of the created or updated records, in input order. Chunks that
+	// failed contribute no ids, and dry runs leave it empty.
+	IDs      []int64
+	Messages []LoadMessage
+	// Rows is the number of input rows processed.
+	Rows int
+}
+
+// HasErrors reports whether any message is an error.
+func (r LoadResult) HasErrors() bool {
+	for _, m := range r.Messages {
+		if m.Type == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+type loadResponse struct {
+	IDs      json.RawMessage `json:"ids"`
+	Messages []LoadMessage   `json:"messages"`
+}
+
+// Load imports rows into model with the ORM `load` method, the same
+// machinery used by the import wizard.
+//
+// fields are import column names: plain fields ("name"), external ids of
+// the records themselves ("id") and relational columns by external id or
+// database id ("partner_id/id", "partner_id/.id") or sub-fields of one2many
+// lines ("order_line/product_id/id"). Values are given as strings, as read
+// from a CSV file.
+func (c *RpcClient) Load(ctx context.Context, model string, fields []string, rows [][]string, opts LoadOptions) (LoadResult, error) {
+	if len(fields) == 0 {
+		return LoadResult{}, errors.New("odoorpc: load requires at least one field")
+	}
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = DefaultLoadChunkSize
+	}
+
+	var result LoadResult
+	for start := 0; start < len(rows); {
+		end := chunkEnd(rows, start, size)
+		chunk := rows[start:end]
+
+		var resp loadResponse
+		var err error
+		if opts.DryRun {
+			resp, err = c.testImport(ctx, model, fields, chunk, opts.Context)
+		} else {
+			resp, err = c.loadChunk(ctx, model, fields, chunk, opts.Context)
+		}
+		if err != nil {
+			return result, fmt.Errorf("load rows %d-%d: %w", start, end-1, err)
+		}
+
+		var ids []int64
+		// A dry run rolls back, its ids do not refer to stored records.
+		if !opts.DryRun && len(resp.IDs) > 0 && string(resp.IDs) != "false" {
+			if err := json.Unmarshal(resp.IDs, &ids); err != nil {
+				return result, fmt.Errorf("load rows %d-%d: unexpected ids: %w", start, end-1, err)
+			}
+		}
+		result.IDs = append(result.IDs, ids...)
+		chunkFailed := false
+		for _, m := range resp.Messages {
+			m.Row = -1
+			switch {
+			case m.Rows != nil:
+				m.Row = start + m.Rows.From
+			case m.Record != nil:
+				m.Row = start + *m.Record
+			}
+			if m.Type == "error" {
+				chunkFailed = true
+			}
+			result.Messages = append(result.Messages, m)
+		}
+		result.Rows += len(chunk)
+		start = end
+		if chunkFailed && opts.StopOnError {
+			break
+		}
+	}
+	return result, nil
+}
+
+// LoadCSV imports a CSV document whose header row holds the import column
+// names. See Load for the accepted column names.
+func (c *RpcClient) LoadCSV(ctx context.Context, model string, r io.Reader, opts LoadOptions) (LoadResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return LoadResult{}, fmt.Errorf("failed to read csv: %w", err)
+	}
+	if len(records) == 0 {
+		return LoadResult{}, errors.New("odoorpc: csv has no header row")
+	}
+	return c.Load(ctx, model, records[0], records[1:], opts)
+}
+
+// chunkEnd returns the end of the chunk starting at start, extending it so
+// that it does not end in the middle of a multi-row record.
+func chunkEnd(rows [][]string, start, size int) int {
+	end := start + size
+	if end >= len(rows) {
+		return len(rows)
+	}
+	for end < len(rows) && (len(rows[end]) == 0 || rows[end][0] == "") {
+		end++
+	}
+	return end
+}
+
+func (c *RpcClient) loadChunk(ctx context.Context, model string, fields []string, rows [][]string, odooContext map[string]any) (loadResponse, error) {
+	var kwargs map[string]any
+	if len(odooContext) > 0 {
+		kwargs = map[string]any{"context": odooContext}
+	}
+	var resp loadResponse
+	err := c.executeKw(ctx, model, "load", []any{fields, rows}, kwargs, &resp)
+	return resp, err
+}
+
+// testImport validates rows through `base_import.import` with dryrun set,
+// which runs the import inside a savepoint that is always rolled back.
+func (c *RpcClient) testImport(ctx context.Context, model string, fields []string, rows [][]string, odooContext map[string]any) (loadResponse, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(fields)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		return loadResponse{}, err
+	}
+
+	var kwargs map[string]any
+	if len(odooContext) > 0 {
+		kwargs = map[string]any{"context": odooContext}
+	}
+	var wizardID int64
+	err := c.executeKw(ctx, "base_import.import", "create", []any{map[string]any{
+		"res_model": model,
+		"file":      base64.StdEncoding.EncodeToString(buf.Bytes()),
+		"file_name": "import.csv",
+		"file_type": "text/csv",
+	}}, kwargs, &wizardID)
+	if err != nil {
+		return loadResponse{}, err
+	}
+	options := map[string]any{
+		"has_headers": true,
+		"separator":   ",",
+		"quoting":     `"`,
+		"encoding":    "utf-8",
+	}
+	var resp loadResponse
+	err = c.executeKw(ctx, "base_import.import", "execute_import", []any{[]any{wizardID}, fields, fields, options, true}, kwargs, &resp)
+	return resp, err
+}
@@ -0,0 +1,76 @@
+package odoorpc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestLoadChunksAndOffsetsMessages(t *testing.T) {
+	var chunks [][]any
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		if call.Method != "load" {
+			return nil, &fakeError{Message: "unexpected " + call.Method}
+		}
+		rows := call.Args[1].([]any)
+		chunks = append(chunks, rows)
+		if len(chunks) == 2 {
+			return map[string]any{
+				"ids":      false,
+				"messages": []any{map[string]any{"type": "error", "message": "bad country", "field": "country_id", "record": 1, "rows": map[string]any{"from": 1, "to": 1}}},
+			}, nil
+		}
+		ids := make([]int, 0, len(rows))
+		for i, row := range rows {
+			if row.([]any)[0] != "" {
+				ids = append(ids, 100+i)
+			}
+		}
+		return map[string]any{"ids": ids, "messages": []any{}}, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+
+	csv := "id,name,order_line/name\n" +
+		"so_1,A,line 1\n" +
+		",,line 2\n" +
+		"so_2,B,line 1\n" +
+		"so_3,C,line 1\n"
+	res, err := c.LoadCSV(context.Background(), "sale.order", strings.NewReader(csv), odoorpc.LoadOptions{ChunkSize: 1})
+	if err != nil {
+		t.Fatalf("LoadCSV: %v", err)
+	}
+	if len(chunks) != 3 || len(chunks[0]) != 2 {
+		t.Fatalf("continuation row split from its record: %v", chunks)
+	}
+	if res.Rows != 4 || len(res.IDs) != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if !res.HasErrors() || res.Messages[0].Row != 3 || res.Messages[0].Field != "country_id" {
+		t.Fatalf("unexpected messages: %+v", res.Messages)
+	}
+}
+
+func TestLoadDryRunUsesImportWizard(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Model == "base_import.import" && call.Method == "create":
+			return 5, nil
+		case call.Model == "base_import.import" && call.Method == "execute_import":
+			if call.Args[4] != true {
+				t.Errorf("expected dryrun flag, got %v", call.Args[4])
+			}
+			return map[string]any{"ids": []int{1}, "messages": []any{}}, nil
+		}
+		return nil, &fakeError{Message: "unexpected " + call.Model + "." + call.Method}
+	})
+	c := odoorpc.New(srv.URL, nil)
+	res, err := c.Load(context.Background(), "res.partner", []string{"name"}, [][]string{{"A"}}, odoorpc.LoadOptions{DryRun: true})
+	if err != nil || res.HasErrors() {
+		t.Fatalf("dry run: %+v %v", res, err)
+	}
+	if len(res.IDs) != 0 {
+		t.Errorf("dry run reported created ids %v", res.IDs)
+	}
+}
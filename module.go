@@ -0,0 +1,355 @@
+package odoorpc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Module states as stored in `ir.module.module.state`.
+const (
+	ModuleUninstallable = "uninstallable"
+	ModuleUninstalled   = "uninstalled"
+	ModuleInstalled     = "installed"
+	ModuleToUpgrade     = "to upgrade"
+	ModuleToRemove      = "to remove"
+	ModuleToInstall     = "to install"
+)
+
+var moduleFields = []string{"name", "state", "shortdesc", "latest_version", "installed_version"}
+
+// Module is an addon record from `ir.module.module`.
+type Module struct {
+	ID               int64
+	Name             string
+	State            string
+	ShortDesc        string
+	LatestVersion    string
+	InstalledVersion string
+}
+
+// ModuleNode is a node of a dependency tree. A module reachable through
+// several paths appears once per path but shares the same node.
+type ModuleNode struct {
+	Module
+	Children []*ModuleNode
+}
+
+// String renders the tree with one module per line, indented by depth.
+func (n *ModuleNode) String() string {
+	var b strings.Builder
+	var walk func(node *ModuleNode, depth int)
+	walk = func(node *ModuleNode, depth int) {
+		fmt.Fprintf(&b, "%s%s (%s)\n", strings.Repeat("  ", depth), node.Name, node.State)
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(n, 0)
+	return b.String()
+}
+
+// ModuleProgress reports the state of the modules being waited on.
+type ModuleProgress struct {
+	// Pending maps the names of modules not yet in their target state to
+	// their current state.
+	Pending map[string]string
+	// Done is the number of modules already in their target state.
+	Done int
+	// Total is the number of modules being waited on.
+	Total int
+}
+
+// ModuleOptions controls how module operations wait for completion.
+type ModuleOptions struct {
+	// PollInterval between state checks, one second when zero.
+	PollInterval time.Duration
+	// Progress, when set, is called after each state check.
+	Progress func(ModuleProgress)
+}
+
+// ModuleStateError is returned when modules do not reach the expected state.
+type ModuleStateError struct {
+	Operation string
+	// States maps each failing module to its current state.
+	States map[string]string
+}
+
+func (e *ModuleStateError) Error() string {
+	names := make([]string, 0, len(e.States))
+	for name := range e.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%s", name, e.States[name])
+	}
+	return fmt.Sprintf("module %s incomplete: %s", e.Operation, strings.Join(parts, ", "))
+}
+
+func moduleFromRecord(rec map[string]any) Module {
+	m := Module{}
+	if id, ok := rec["id"].(float64); ok {
+		m.ID = int64(id)
+	}
+	m.Name, _ = rec["name"].(string)
+	m.State, _ = rec["state"].(string)
+	m.ShortDesc, _ = rec["shortdesc"].(string)
+	// Odoo returns false for empty char fields.
+	m.LatestVersion, _ = rec["latest_version"].(string)
+	m.InstalledVersion, _ = rec["installed_version"].(string)
+	return m
+}
+
+// UpdateModuleList rescans the addons paths, like "Update Apps List" in the
+// UI, and returns the number of updated and added modules.
+func (c *RpcClient) UpdateModuleList(ctx context.Context) (updated, added int, err error) {
+	var res []int
+	if err := c.executeKw(ctx, "ir.module.module", "update_list", nil, nil, &res); err != nil {
+		return 0, 0, err
+	}
+	if len(res) == 2 {
+		updated, added = res[0], res[1]
+	}
+	return updated, added, nil
+}
+
+// Modules resolves module technical names to their records. It fails if any
+// of the names is unknown to the server.
+func (c *RpcClient) Modules(ctx context.Context, names ...string) ([]Module, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	recs, err := c.SearchRead(ctx, "ir.module.module", NewDomain().InStrings("name", names), Options{Fields: moduleFields})
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Module, len(recs))
+	for _, rec := range recs {
+		m := moduleFromRecord(rec)
+		byName[m.Name] = m
+	}
+	var missing []string
+	modules := make([]Module, 0, len(names))
+	for _, name := range names {
+		m, ok := byName[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		modules = append(modules, m)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unknown modules: %s", strings.Join(missing, ", "))
+	}
+	return modules, nil
+}
+
+// InstallModules installs the named modules and their dependencies, then
+// waits until all of them are installed.
+func (c *RpcClient) InstallModules(ctx context.Context, names []string, opts ModuleOptions) error {
+	return c.runModuleButton(ctx, "install", "button_immediate_install", names, ModuleInstalled, opts)
+}
+
+// UpgradeModules upgrades the named modules, then waits until all of them
+// are back in the installed state.
+func (c *RpcClient) UpgradeModules(ctx context.Context, names []string, opts ModuleOptions) error {
+	return c.runModuleButton(ctx, "upgrade", "button_immediate_upgrade", names, ModuleInstalled, opts)
+}
+
+// UninstallModules uninstalls the named modules and the modules depending on
+// them, then waits until all of them are uninstalled.
+func (c *RpcClient) UninstallModules(ctx context.Context, names []string, opts ModuleOptions) error {
+	return c.runModuleButton(ctx, "uninstall", "button_immediate_uninstall", names, ModuleUninstalled, opts)
+}
+
+func (c *RpcClient) runModuleButton(ctx context.Context, op, button string, names []string, target string, opts ModuleOptions) error {
+	modules, err := c.Modules(ctx, names...)
+	if err != nil {
+		return err
+	}
+	ids := make([]any, len(modules))
+	for i, m := range modules {
+		if m.State == ModuleUninstallable {
+			return &ModuleStateError{Operation: op, States: map[string]string{m.Name: m.State}}
+		}
+		ids[i] = m.ID
+	}
+	// The immediate buttons return a client action reloading the web
+	// client; it carries nothing useful over RPC.
+	if err := c.executeKw(ctx, "ir.module.module", button, []any{ids}, nil, nil); err != nil {
+		return fmt.Errorf("module %s failed: %w", op, err)
+	}
+	return c.WaitModules(ctx, names, target, opts)
+}
+
+// WaitModules polls the named modules until all of them reach the target
+// state, reporting progress through opts.Progress. It returns a
+// *ModuleStateError if the modules settle in another state, and the context
+// error if ctx is done first.
+func (c *RpcClient) WaitModules(ctx context.Context, names []string, target string, opts ModuleOptions) error {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		modules, err := c.Modules(ctx, names...)
+		if err != nil {
+			return err
+		}
+		progress := ModuleProgress{Pending: map[string]string{}, Total: len(modules)}
+		settled := true
+		for _, m := range modules {
+			if m.State == target {
+				progress.Done++
+				continue
+			}
+			progress.Pending[m.Name] = m.State
+			if strings.HasPrefix(m.State, "to ") {
+				settled = false
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+		if len(progress.Pending) == 0 {
+			return nil
+		}
+		if settled {
+			return &ModuleStateError{Operation: "wait", States: progress.Pending}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ModuleDependencies returns the tree of modules the named module depends on.
+func (c *RpcClient) ModuleDependencies(ctx context.Context, name string) (*ModuleNode, error) {
+	return c.moduleTree(ctx, name, func(ctx context.Context, mods []Module) (map[string][]string, error) {
+		ids := make([]int64, len(mods))
+		for i, m := range mods {
+			ids[i] = m.ID
+		}
+		recs, err := c.SearchRead(ctx, "ir.module.module.dependency", NewDomain().In("module_id", ids), Options{Fields: []string{"name", "module_id"}})
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]string, len(mods))
+		for _, m := range mods {
+			byID[m.ID] = m.Name
+		}
+		edges := map[string][]string{}
+		for _, rec := range recs {
+			dep, _ := rec["name"].(string)
+			owner := byID[many2oneID(rec["module_id"])]
+			edges[owner] = append(edges[owner], dep)
+		}
+		return edges, nil
+	})
+}
+
+// ModuleReverseDependencies returns the tree of modules depending on the
+// named module, i.e. those affected by upgrading or uninstalling it.
+func (c *RpcClient) ModuleReverseDependencies(ctx context.Context, name string) (*ModuleNode, error) {
+	return c.moduleTree(ctx, name, func(ctx context.Context, mods []Module) (map[string][]string, error) {
+		names := make([]string, len(mods))
+		for i, m := range mods {
+			names[i] = m.Name
+		}
+		recs, err := c.SearchRead(ctx, "ir.module.module.dependency", NewDomain().InStrings("name", names), Options{Fields: []string{"name", "module_id"}})
+		if err != nil {
+			return nil, err
+		}
+		// The many2one display name is the module title, so resolve the
+		// owners' technical names by id.
+		var ownerIDs []int64
+		for _, rec := range recs {
+			ownerIDs = append(ownerIDs, many2oneID(rec["module_id"]))
+		}
+		owners, err := c.Read(ctx, "ir.module.module", ownerIDs, Options{Fields: []string{"name"}})
+		if err != nil {
+			return nil, err
+		}
+		ownerNames := make(map[int64]string, len(owners))
+		for _, owner := range owners {
+			m := moduleFromRecord(owner)
+			ownerNames[m.ID] = m.Name
+		}
+		edges := map[string][]string{}
+		for _, rec := range recs {
+			dep, _ := rec["name"].(string)
+			if owner, ok := ownerNames[many2oneID(rec["module_id"])]; ok && !slices.Contains(edges[dep], owner) {
+				edges[dep] = append(edges[dep], owner)
+			}
+		}
+		return edges, nil
+	})
+}
+
+// moduleTree builds a tree breadth first, resolving one level per round trip.
+func (c *RpcClient) moduleTree(ctx context.Context, name string, next func(context.Context, []Module) (map[string][]string, error)) (*ModuleNode, error) {
+	roots, err := c.Modules(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	root := &ModuleNode{Module: roots[0]}
+	nodes := map[string]*ModuleNode{name: root}
+	frontier := []Module{root.Module}
+	for len(frontier) > 0 {
+		edges, err := next(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+		var unseen []string
+		for _, m := range frontier {
+			for _, child := range edges[m.Name] {
+				if _, ok := nodes[child]; !ok && !slices.Contains(unseen, child) {
+					unseen = append(unseen, child)
+				}
+			}
+		}
+		var resolved []Module
+		if len(unseen) > 0 {
+			recs, err := c.SearchRead(ctx, "ir.module.module", NewDomain().InStrings("name", unseen), Options{Fields: moduleFields})
+			if err != nil {
+				return nil, err
+			}
+			for _, rec := range recs {
+				m := moduleFromRecord(rec)
+				nodes[m.Name] = &ModuleNode{Module: m}
+				resolved = append(resolved, m)
+			}
+		}
+		for _, m := range frontier {
+			parent := nodes[m.Name]
+			for _, child := range edges[m.Name] {
+				node, ok := nodes[child]
+				if !ok {
+					// Dependency declared but not present in the addons path.
+					node = &ModuleNode{Module: Module{Name: child, State: "unknown"}}
+					nodes[child] = node
+				}
+				parent.Children = append(parent.Children, node)
+			}
+		}
+		frontier = resolved
+	}
+	return root, nil
+}
+
+// many2oneID extracts the id of a many2one value ([id, display_name] or false).
+func many2oneID(v any) int64 {
+	pair, ok := v.([]any)
+	if !ok || len(pair) == 0 {
+		return 0
+	}
+	id, _ := pair[0].(float64)
+	return int64(id)
+}
@@ -0,0 +1,97 @@
+package odoorpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestInstallModulesWaitsForState(t *testing.T) {
+	state := "uninstalled"
+	polls := 0
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "search_read":
+			if state == "to install" {
+				polls++
+				if polls > 1 {
+					state = "installed"
+				}
+			}
+			return []map[string]any{{"id": 7, "name": "sale", "state": state, "shortdesc": "Sales"}}, nil
+		case "button_immediate_install":
+			state = "to install"
+			return map[string]any{"type": "ir.actions.client", "tag": "reload"}, nil
+		}
+		return nil, &fakeError{Message: "unexpected " + call.Method}
+	})
+	c := odoorpc.New(srv.URL, nil)
+
+	var reports []odoorpc.ModuleProgress
+	opts := odoorpc.ModuleOptions{PollInterval: 1, Progress: func(p odoorpc.ModuleProgress) { reports = append(reports, p) }}
+	if err := c.InstallModules(context.Background(), []string{"sale"}, opts); err != nil {
+		t.Fatalf("InstallModules: %v", err)
+	}
+	if len(reports) < 2 || reports[len(reports)-1].Done != 1 {
+		t.Fatalf("unexpected progress reports: %+v", reports)
+	}
+}
+
+func TestInstallModulesFailedState(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		if call.Method == "search_read" {
+			return []map[string]any{{"id": 7, "name": "sale", "state": "uninstalled"}}, nil
+		}
+		return true, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	err := c.InstallModules(context.Background(), []string{"sale"}, odoorpc.ModuleOptions{PollInterval: 1})
+	var stateErr *odoorpc.ModuleStateError
+	if !errors.As(err, &stateErr) || stateErr.States["sale"] != "uninstalled" {
+		t.Fatalf("expected ModuleStateError, got %v", err)
+	}
+	if _, err := c.Modules(context.Background(), "sale", "missing"); err == nil {
+		t.Fatalf("expected unknown module error")
+	}
+}
+
+func TestModuleDependencies(t *testing.T) {
+	modules := map[string]int{"sale": 1, "account": 2, "base": 3}
+	deps := map[string][]string{"sale": {"account", "base"}, "account": {"base"}}
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		cond := call.Args[0].([]any)[0].([]any)
+		switch call.Model {
+		case "ir.module.module":
+			var recs []map[string]any
+			for _, name := range cond[2].([]any) {
+				recs = append(recs, map[string]any{"id": modules[name.(string)], "name": name, "state": "installed"})
+			}
+			return recs, nil
+		case "ir.module.module.dependency":
+			var recs []map[string]any
+			for _, id := range cond[2].([]any) {
+				for name, mid := range modules {
+					if float64(mid) != id.(float64) {
+						continue
+					}
+					for _, dep := range deps[name] {
+						recs = append(recs, map[string]any{"name": dep, "module_id": []any{mid, name}})
+					}
+				}
+			}
+			return recs, nil
+		}
+		return nil, &fakeError{Message: "unexpected model " + call.Model}
+	})
+	c := odoorpc.New(srv.URL, nil)
+	tree, err := c.ModuleDependencies(context.Background(), "sale")
+	if err != nil {
+		t.Fatalf("ModuleDependencies: %v", err)
+	}
+	want := "sale (installed)\n  account (installed)\n    base (installed)\n  base (installed)\n"
+	if tree.String() != want {
+		t.Fatalf("unexpected tree:\n%s", tree)
+	}
+}
@@ -0,0 +1,396 @@
+// Package openapi generates OpenAPI 3.1 documents describing Odoo models,
+// from the field definitions returned by fields_get, with the operations
+// served by the rest package.
+//
+//	models := map[string]rest.Model{"res.partner": {Fields: []string{"name", "email"}}}
+//	doc, err := openapi.Generate(ctx, client, openapi.Options{Title: "ERP", Models: models})
+//	json.NewEncoder(os.Stdout).Encode(doc)
+//
+// Each model gets three schemas: "<model>" for records as read,
+// "<model>.input" for write bodies, which leaves out readonly fields and
+// takes relations as ids, and "<model>.create" for create bodies, which
+// also requires the mandatory fields without a default value. Since
+// fields_get reflects the database, custom fields (x_*) of each customer
+// are included.
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/rest"
+)
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info holds the title and version of the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is a base URL serving the API.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Components holds the schemas referenced by the document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem lists the operations served on a path.
+type PathItem struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Get        *Operation  `json:"get,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+}
+
+// Operation describes an HTTP method on a path.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body accepted by an operation.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation for a status code.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in a given content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON Schema as used by OpenAPI 3.1. Type holds a string or,
+// for values that may be false, a list of types.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        any                `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	PrefixItems []*Schema          `json:"prefixItems,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	ReadOnly    bool               `json:"readOnly,omitempty"`
+	// Relation is the comodel of relational fields.
+	Relation string `json:"x-odoo-relation,omitempty"`
+	// FieldType is the Odoo field type.
+	FieldType string `json:"x-odoo-type,omitempty"`
+}
+
+// Options configures Generate.
+type Options struct {
+	Title   string
+	Version string
+	// ServerURL is the base URL where the gateway is mounted.
+	ServerURL string
+	// Models are the documented models, with the same policies as the
+	// rest.Gateway serving them.
+	Models map[string]rest.Model
+	// Context is passed to fields_get, e.g. to set "lang" for the field
+	// labels and help texts.
+	Context map[string]any
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Generate describes opts.Models, reading their fields from Odoo.
+func Generate(ctx context.Context, client odoorpc.Client, opts Options) (*Document, error) {
+	doc := &Document{
+		OpenAPI:    "3.1.0",
+		Info:       Info{Title: opts.Title, Version: opts.Version},
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{"Error": errorSchema()}},
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = "Odoo"
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = "1"
+	}
+	if opts.ServerURL != "" {
+		doc.Servers = []Server{{URL: opts.ServerURL}}
+	}
+	for _, model := range slices.Sorted(maps.Keys(opts.Models)) {
+		policy := opts.Models[model]
+		fields, err := client.FieldsGet(ctx, model, policy.Fields, odoorpc.Options{Context: opts.Context})
+		if err != nil {
+			return nil, fmt.Errorf("openapi: %s: %w", model, err)
+		}
+		read, input := modelSchemas(fields)
+		doc.Components.Schemas[model] = read
+		if !policy.ReadOnly {
+			create, err := createSchema(ctx, client, model, fields, input, opts.Context)
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s: %w", model, err)
+			}
+			doc.Components.Schemas[model+".input"] = input
+			doc.Components.Schemas[model+".create"] = create
+		}
+		addPaths(doc, model, policy)
+	}
+	return doc, nil
+}
+
+func errorSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"message": {Type: "string"},
+					"type":    {Type: "string", Description: "Odoo exception class"},
+				},
+			},
+		},
+	}
+}
+
+// modelSchemas builds the read and input schemas from fields_get output.
+func modelSchemas(fields map[string]any) (read, input *Schema) {
+	read = &Schema{Type: "object", Properties: map[string]*Schema{"id": {Type: "integer", ReadOnly: true}}, Required: []string{"id"}}
+	input = &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		attrs, _ := fields[name].(map[string]any)
+		if name == "id" || attrs == nil {
+			continue
+		}
+		ftype, _ := attrs["type"].(string)
+		required, _ := attrs["required"].(bool)
+		readonly, _ := attrs["readonly"].(bool)
+
+		rs := fieldSchema(ftype, attrs, false)
+		if rs == nil {
+			continue
+		}
+		rs.ReadOnly = readonly
+		if required {
+			read.Required = append(read.Required, name)
+		} else if ftype != "boolean" && ftype != "one2many" && ftype != "many2many" {
+			// Odoo returns false for empty values.
+			rs.Type = []string{rs.Type.(string), "boolean"}
+			if rs.Enum != nil {
+				rs.Enum = append(rs.Enum, false)
+			}
+		}
+		read.Properties[name] = rs
+
+		if !readonly {
+			input.Properties[name] = fieldSchema(ftype, attrs, true)
+		}
+	}
+	return read, input
+}
+
+// createSchema returns input with the required writable fields that have
+// no default value, as told by default_get, marked as required.
+func createSchema(ctx context.Context, client odoorpc.Client, model string, fields map[string]any, input *Schema, callContext map[string]any) (*Schema, error) {
+	var mandatory []string
+	for _, name := range slices.Sorted(maps.Keys(input.Properties)) {
+		attrs, _ := fields[name].(map[string]any)
+		if required, _ := attrs["required"].(bool); required {
+			mandatory = append(mandatory, name)
+		}
+	}
+	create := *input
+	if len(mandatory) == 0 {
+		return &create, nil
+	}
+	res, err := client.CallMethod(ctx, model, "default_get", []any{mandatory}, odoorpc.Options{Context: callContext})
+	if err != nil {
+		return nil, err
+	}
+	var defaults map[string]any
+	if len(res) > 0 {
+		defaults, _ = res[0].(map[string]any)
+	}
+	for _, name := range mandatory {
+		if _, ok := defaults[name]; !ok {
+			create.Required = append(create.Required, name)
+		}
+	}
+	return &create, nil
+}
+
+// fieldSchema maps an Odoo field type. Relations are [id, name] pairs for
+// reading and ids for writing many2one fields, and lists of ids for x2many
+// fields both ways.
+func fieldSchema(ftype string, attrs map[string]any, input bool) *Schema {
+	s := &Schema{FieldType: ftype}
+	s.Title, _ = attrs["string"].(string)
+	s.Description, _ = attrs["help"].(string)
+	s.Relation, _ = attrs["relation"].(string)
+	switch ftype {
+	case "char", "text", "html":
+		s.Type = "string"
+	case "selection":
+		s.Type = "string"
+		if sel, ok := attrs["selection"].([]any); ok {
+			for _, opt := range sel {
+				if pair, ok := opt.([]any); ok && len(pair) == 2 {
+					s.Enum = append(s.Enum, pair[0])
+				}
+			}
+		}
+	case "integer":
+		s.Type, s.Format = "integer", "int64"
+	case "float", "monetary":
+		s.Type, s.Format = "number", "double"
+	case "boolean":
+		s.Type = "boolean"
+	case "date":
+		s.Type, s.Format = "string", "date"
+	case "datetime":
+		s.Type, s.Format = "string", "date-time"
+		s.Description = appendNote(s.Description, `UTC, formatted "YYYY-MM-DD HH:MM:SS".`)
+	case "binary":
+		s.Type, s.Format = "string", "byte"
+	case "many2one":
+		if input {
+			s.Type = "integer"
+		} else {
+			s.Type = "array"
+			s.PrefixItems = []*Schema{{Type: "integer"}, {Type: "string"}}
+		}
+	case "one2many", "many2many":
+		s.Type = "array"
+		s.Items = &Schema{Type: "integer"}
+	case "json", "properties":
+		s.Type = "object"
+	default:
+		// reference, many2one_reference and unknown custom types are
+		// described as strings.
+		if ftype == "" {
+			return nil
+		}
+		s.Type = "string"
+	}
+	return s
+}
+
+func appendNote(desc, note string) string {
+	if desc == "" {
+		return note
+	}
+	return desc + "\n\n" + note
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+func errorResponses(codes ...string) map[string]*Response {
+	responses := map[string]*Response{}
+	for _, code := range codes {
+		responses[code] = &Response{Description: "Error", Content: jsonContent(ref("Error"))}
+	}
+	return responses
+}
+
+func addPaths(doc *Document, model string, policy rest.Model) {
+	tags := []string{model}
+	listParams := []Parameter{
+		{Name: "domain", In: "query", Description: "Odoo domain, e.g. [('is_company', '=', True)]", Schema: &Schema{Type: "string"}},
+		{Name: "fields", In: "query", Description: "Comma separated field names", Schema: &Schema{Type: "string"}},
+		{Name: "limit", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "offset", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "order", In: "query", Description: "e.g. name desc, id", Schema: &Schema{Type: "string"}},
+	}
+	list := &Operation{
+		OperationID: "list_" + model,
+		Summary:     "Search " + model,
+		Tags:        tags,
+		Parameters:  listParams,
+		Responses:   errorResponses("400", "403"),
+	}
+	list.Responses["200"] = &Response{Description: "Matching records", Content: jsonContent(&Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"records": {Type: "array", Items: ref(model)},
+			"limit":   {Type: "integer"},
+			"offset":  {Type: "integer"},
+		},
+	})}
+	collection := &PathItem{Get: list}
+
+	idParam := Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}}
+	read := &Operation{
+		OperationID: "read_" + model,
+		Summary:     "Read a " + model + " record",
+		Tags:        tags,
+		Parameters:  []Parameter{{Name: "fields", In: "query", Schema: &Schema{Type: "string"}}},
+		Responses:   errorResponses("403", "404"),
+	}
+	read.Responses["200"] = &Response{Description: "The record", Content: jsonContent(ref(model))}
+	item := &PathItem{Parameters: []Parameter{idParam}, Get: read}
+
+	if !policy.ReadOnly {
+		body := &RequestBody{Required: true, Content: jsonContent(ref(model + ".input"))}
+		collection.Post = &Operation{
+			OperationID: "create_" + model,
+			Summary:     "Create a " + model + " record",
+			Tags:        tags,
+			RequestBody: &RequestBody{Required: true, Content: jsonContent(ref(model + ".create"))},
+			Responses:   errorResponses("400", "403", "422"),
+		}
+		collection.Post.Responses["201"] = &Response{Description: "Created", Content: jsonContent(&Schema{
+			Type: "object", Properties: map[string]*Schema{"id": {Type: "integer"}},
+		})}
+		item.Patch = &Operation{
+			OperationID: "write_" + model,
+			Summary:     "Update a " + model + " record",
+			Tags:        tags,
+			RequestBody: body,
+			Responses:   errorResponses("400", "403", "404", "422"),
+		}
+		item.Patch.Responses["204"] = &Response{Description: "Updated"}
+		item.Delete = &Operation{
+			OperationID: "unlink_" + model,
+			Summary:     "Delete a " + model + " record",
+			Tags:        tags,
+			Responses:   errorResponses("403", "404", "422"),
+		}
+		item.Delete.Responses["204"] = &Response{Description: "Deleted"}
+	}
+	doc.Paths["/models/"+model] = collection
+	doc.Paths["/models/"+model+"/{id}"] = item
+}
@@ -0,0 +1,86 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/openapi"
+	"github.com/Guadalsistema/odoorpc/rest"
+)
+
+type fieldsClient struct {
+	odoorpc.Client
+}
+
+func (fieldsClient) FieldsGet(ctx context.Context, model string, fields []string, opts odoorpc.Options) (map[string]any, error) {
+	return map[string]any{
+		"name":         map[string]any{"type": "char", "string": "Name", "required": true},
+		"state":        map[string]any{"type": "selection", "selection": []any{[]any{"draft", "Draft"}, []any{"sale", "Sales Order"}}},
+		"partner_id":   map[string]any{"type": "many2one", "relation": "res.partner", "required": true},
+		"order_line":   map[string]any{"type": "one2many", "relation": "sale.order.line"},
+		"amount_total": map[string]any{"type": "monetary", "readonly": true},
+		"x_po_ref":     map[string]any{"type": "char", "string": "Customer PO"},
+	}, nil
+}
+
+// default_get provides a default for name only.
+func (fieldsClient) CallMethod(ctx context.Context, model, method string, vars []any, opts odoorpc.Options) ([]any, error) {
+	return []any{map[string]any{"name": "New"}}, nil
+}
+
+func TestGenerateDescribesSchemasAndOperations(t *testing.T) {
+	doc, err := openapi.Generate(context.Background(), fieldsClient{}, openapi.Options{
+		Title:  "ERP",
+		Models: map[string]rest.Model{"sale.order": {}, "product.product": {ReadOnly: true}},
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	read := doc.Components.Schemas["sale.order"]
+	if !reflect.DeepEqual(read.Required, []string{"id", "name", "partner_id"}) {
+		t.Errorf("required = %v", read.Required)
+	}
+	if got := read.Properties["state"].Enum; !reflect.DeepEqual(got, []any{"draft", "sale", false}) {
+		t.Errorf("selection enum = %v", got)
+	}
+	if got := read.Properties["x_po_ref"].Type; !reflect.DeepEqual(got, []string{"string", "boolean"}) {
+		t.Errorf("optional fields may be false, got type %v", got)
+	}
+	if p := read.Properties["partner_id"]; p.Type != "array" || p.Relation != "res.partner" {
+		t.Errorf("many2one read schema = %+v", p)
+	}
+	if !read.Properties["amount_total"].ReadOnly {
+		t.Errorf("amount_total should be readonly")
+	}
+
+	input := doc.Components.Schemas["sale.order.input"]
+	if _, ok := input.Properties["amount_total"]; ok {
+		t.Errorf("readonly fields must not be writable")
+	}
+	if p := input.Properties["partner_id"]; p.Type != "integer" {
+		t.Errorf("many2one input schema = %+v", p)
+	}
+	if len(input.Required) != 0 {
+		t.Errorf("write bodies require nothing, got %v", input.Required)
+	}
+	if create := doc.Components.Schemas["sale.order.create"]; !reflect.DeepEqual(create.Required, []string{"partner_id"}) {
+		t.Errorf("create required = %v", create.Required)
+	}
+	if _, ok := doc.Components.Schemas["product.product.input"]; ok {
+		t.Errorf("read only models have no input schema")
+	}
+
+	order := doc.Paths["/models/sale.order/{id}"]
+	if order.Get == nil || order.Patch == nil || order.Delete == nil || doc.Paths["/models/sale.order"].Post == nil {
+		t.Errorf("missing CRUD operations on sale.order")
+	}
+	if doc.Paths["/models/product.product"].Post != nil || doc.Paths["/models/product.product/{id}"].Delete != nil {
+		t.Errorf("read only models must not have write operations")
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Errorf("marshal: %v", err)
+	}
+}
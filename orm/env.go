@@ -0,0 +1,274 @@
+// Package orm provides an Odoo style recordset API on top of
+// odoorpc.Client.
+//
+//	env := orm.NewEnv(client)
+//	partners, err := env.Model("res.partner").Search(ctx, odoorpc.NewDomain().Equals("is_company", true), odoorpc.Options{})
+//	codes, err := partners.Mapped(ctx, "country_id.code")
+//
+// Field values are cached per Env. Reading a field on any record reads it
+// for every record of the recordset it was obtained from, in batches, like
+// Odoo's prefetching, so iterating a recordset does not issue one call per
+// record.
+package orm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// DefaultPrefetchSize is the maximum number of records read per call when
+// prefetching fields.
+const DefaultPrefetchSize = 1000
+
+// contextClient is implemented by clients able to attach a context to
+// every call, such as *odoorpc.RpcClient.
+type contextClient interface {
+	WithContext(values map[string]any) *odoorpc.RpcClient
+}
+
+// Env gives access to models through a client and holds the record cache.
+type Env struct {
+	client  odoorpc.Client
+	context map[string]any
+	// PrefetchSize bounds the number of records read per call.
+	PrefetchSize int
+
+	mu     sync.Mutex
+	cache  map[string]map[int64]map[string]any
+	fields map[string]map[string]fieldInfo
+}
+
+type fieldInfo struct {
+	Type     string
+	Relation string
+}
+
+func (f fieldInfo) relational() bool {
+	switch f.Type {
+	case "many2one", "one2many", "many2many":
+		return true
+	}
+	return false
+}
+
+// NewEnv creates an environment over client.
+func NewEnv(client odoorpc.Client) *Env {
+	return &Env{
+		client:       client,
+		PrefetchSize: DefaultPrefetchSize,
+		cache:        map[string]map[int64]map[string]any{},
+		fields:       map[string]map[string]fieldInfo{},
+	}
+}
+
+// WithContext returns an environment whose calls run with values merged in
+// the context, with an empty cache since values may differ (e.g. "lang").
+//
+// Reads always receive the context. Writes only do when the client supports
+// a default context, as *odoorpc.RpcClient does.
+func (e *Env) WithContext(values map[string]any) *Env {
+	merged := maps.Clone(e.context)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	maps.Copy(merged, values)
+	client := e.client
+	if cc, ok := client.(contextClient); ok {
+		client = cc.WithContext(values)
+	}
+	env := NewEnv(client)
+	env.context = merged
+	env.PrefetchSize = e.PrefetchSize
+	return env
+}
+
+// Client returns the underlying client.
+func (e *Env) Client() odoorpc.Client {
+	return e.client
+}
+
+// Model returns the model called name.
+func (e *Env) Model(name string) *Model {
+	return &Model{env: e, name: name}
+}
+
+// Invalidate drops every cached field value.
+func (e *Env) Invalidate() {
+	e.mu.Lock()
+	e.cache = map[string]map[int64]map[string]any{}
+	e.mu.Unlock()
+}
+
+func (e *Env) options(opts odoorpc.Options) odoorpc.Options {
+	if len(e.context) == 0 {
+		return opts
+	}
+	merged := maps.Clone(e.context)
+	maps.Copy(merged, opts.Context)
+	opts.Context = merged
+	return opts
+}
+
+// fieldInfo returns the metadata of a field, loading the model fields once.
+func (e *Env) fieldInfo(ctx context.Context, model, field string) (fieldInfo, error) {
+	e.mu.Lock()
+	fields, ok := e.fields[model]
+	e.mu.Unlock()
+	if !ok {
+		res, err := e.client.FieldsGet(ctx, model, nil, e.options(odoorpc.Options{}))
+		if err != nil {
+			return fieldInfo{}, err
+		}
+		fields = make(map[string]fieldInfo, len(res))
+		for name, raw := range res {
+			attrs, _ := raw.(map[string]any)
+			info := fieldInfo{}
+			info.Type, _ = attrs["type"].(string)
+			info.Relation, _ = attrs["relation"].(string)
+			fields[name] = info
+		}
+		e.mu.Lock()
+		e.fields[model] = fields
+		e.mu.Unlock()
+	}
+	info, ok := fields[field]
+	if !ok {
+		return fieldInfo{}, fmt.Errorf("field %s.%s does not exist", model, field)
+	}
+	return info, nil
+}
+
+func (e *Env) cached(model string, id int64, field string) (any, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v, ok := e.cache[model][id][field]
+	return v, ok
+}
+
+func (e *Env) store(model string, recs []map[string]any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	records := e.cache[model]
+	if records == nil {
+		records = map[int64]map[string]any{}
+		e.cache[model] = records
+	}
+	for _, rec := range recs {
+		id, ok := toID(rec["id"])
+		if !ok {
+			continue
+		}
+		values := records[id]
+		if values == nil {
+			values = map[string]any{}
+			records[id] = values
+		}
+		maps.Copy(values, rec)
+	}
+}
+
+func (e *Env) forget(model string, ids []int64, fields []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, id := range ids {
+		if fields == nil {
+			delete(e.cache[model], id)
+			continue
+		}
+		for _, f := range fields {
+			delete(e.cache[model][id], f)
+		}
+	}
+}
+
+// Model is an Odoo model within an environment.
+type Model struct {
+	env  *Env
+	name string
+}
+
+// Name returns the technical name of the model.
+func (m *Model) Name() string {
+	return m.name
+}
+
+// Browse returns a recordset for ids without contacting the server.
+func (m *Model) Browse(ids ...int64) *Recordset {
+	return m.env.browse(m.name, ids)
+}
+
+// Search returns the records matching domain.
+func (m *Model) Search(ctx context.Context, domain odoorpc.Domain, opts odoorpc.Options) (*Recordset, error) {
+	ids, err := m.env.client.Search(ctx, m.name, domain, m.env.options(opts))
+	if err != nil {
+		return nil, err
+	}
+	return m.Browse(ids...), nil
+}
+
+// Create creates a record and returns it as a recordset.
+func (m *Model) Create(ctx context.Context, values map[string]any) (*Recordset, error) {
+	id, err := m.env.client.Create(ctx, m.name, values)
+	if err != nil {
+		return nil, err
+	}
+	return m.Browse(id), nil
+}
+
+func (e *Env) browse(model string, ids []int64) *Recordset {
+	ids = dedupe(ids)
+	return &Recordset{env: e, model: model, ids: ids, prefetch: ids}
+}
+
+func dedupe(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func toID(v any) (int64, bool) {
+	switch id := v.(type) {
+	case float64:
+		return int64(id), true
+	case int64:
+		return id, true
+	case int:
+		return int64(id), true
+	}
+	return 0, false
+}
+
+// relatedIDs extracts record ids from a many2one ([id, name]) or x2many
+// ([id, ...]) value.
+func relatedIDs(info fieldInfo, v any) []int64 {
+	list, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	if info.Type == "many2one" {
+		if len(list) == 0 {
+			return nil
+		}
+		if id, ok := toID(list[0]); ok {
+			return []int64{id}
+		}
+		return nil
+	}
+	ids := make([]int64, 0, len(list))
+	for _, item := range list {
+		if id, ok := toID(item); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
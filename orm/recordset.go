@@ -0,0 +1,334 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// ErrModelMismatch is returned when combining recordsets of different models.
+var ErrModelMismatch = errors.New("recordsets belong to different models")
+
+// Recordset is an ordered set of records of one model.
+type Recordset struct {
+	env   *Env
+	model string
+	ids   []int64
+	// prefetch are the records whose fields are read together with ours.
+	prefetch []int64
+}
+
+// Model returns the technical name of the model of the records.
+func (r *Recordset) Model() string {
+	return r.model
+}
+
+// IDs returns the record ids in order.
+func (r *Recordset) IDs() []int64 {
+	return slices.Clone(r.ids)
+}
+
+// Len returns the number of records.
+func (r *Recordset) Len() int {
+	return len(r.ids)
+}
+
+// IsEmpty reports whether the recordset has no records.
+func (r *Recordset) IsEmpty() bool {
+	return len(r.ids) == 0
+}
+
+func (r *Recordset) String() string {
+	return fmt.Sprintf("%s%v", r.model, r.ids)
+}
+
+// Records returns one singleton recordset per record. Singletons keep
+// prefetching for the whole recordset.
+func (r *Recordset) Records() []*Recordset {
+	out := make([]*Recordset, len(r.ids))
+	for i, id := range r.ids {
+		out[i] = &Recordset{env: r.env, model: r.model, ids: []int64{id}, prefetch: r.prefetch}
+	}
+	return out
+}
+
+// EnsureOne returns an error unless the recordset holds exactly one record.
+func (r *Recordset) EnsureOne() error {
+	if len(r.ids) != 1 {
+		return fmt.Errorf("expected singleton: %s", r)
+	}
+	return nil
+}
+
+// ID returns the id of a singleton recordset.
+func (r *Recordset) ID() (int64, error) {
+	if err := r.EnsureOne(); err != nil {
+		return 0, err
+	}
+	return r.ids[0], nil
+}
+
+// Read reads fields, or all fields when none is given, for all the records
+// in batches and caches them. It returns one map per record in recordset
+// order.
+func (r *Recordset) Read(ctx context.Context, fields ...string) ([]map[string]any, error) {
+	recs, err := r.fetch(ctx, r.ids, fields)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]map[string]any, len(recs))
+	for _, rec := range recs {
+		if id, ok := toID(rec["id"]); ok {
+			byID[id] = rec
+		}
+	}
+	res := make([]map[string]any, 0, len(r.ids))
+	for _, id := range r.ids {
+		if rec, ok := byID[id]; ok {
+			res = append(res, rec)
+		}
+	}
+	return res, nil
+}
+
+// Value returns field of a singleton recordset, reading it for the whole
+// prefetch set when it is not cached.
+func (r *Recordset) Value(ctx context.Context, field string) (any, error) {
+	id, err := r.ID()
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := r.env.cached(r.model, id, field); ok {
+		return v, nil
+	}
+	if _, err := r.fetch(ctx, r.missing(field), []string{field}); err != nil {
+		return nil, err
+	}
+	v, _ := r.env.cached(r.model, id, field)
+	return v, nil
+}
+
+// missing returns the prefetch ids whose field is not cached.
+func (r *Recordset) missing(field string) []int64 {
+	var ids []int64
+	for _, id := range r.prefetch {
+		if _, ok := r.env.cached(r.model, id, field); !ok {
+			ids = append(ids, id)
+		}
+	}
+	if !slices.Contains(ids, r.ids[0]) {
+		ids = append(ids, r.ids[0])
+	}
+	return ids
+}
+
+func (r *Recordset) fetch(ctx context.Context, ids []int64, fields []string) ([]map[string]any, error) {
+	size := r.env.PrefetchSize
+	if size <= 0 {
+		size = DefaultPrefetchSize
+	}
+	var all []map[string]any
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+		recs, err := r.env.client.Read(ctx, r.model, ids[start:end], r.env.options(odoorpc.Options{Fields: fields}))
+		if err != nil {
+			return nil, err
+		}
+		r.env.store(r.model, recs)
+		all = append(all, recs...)
+	}
+	return all, nil
+}
+
+// Write updates all the records with values and invalidates their cache.
+func (r *Recordset) Write(ctx context.Context, values map[string]any) error {
+	if r.IsEmpty() {
+		return nil
+	}
+	if _, err := r.env.client.Update(ctx, r.model, r.ids, values); err != nil {
+		return err
+	}
+	// Computed fields may depend on the written ones, so drop everything.
+	r.env.forget(r.model, r.ids, nil)
+	return nil
+}
+
+// Unlink deletes the records.
+func (r *Recordset) Unlink(ctx context.Context) error {
+	if r.IsEmpty() {
+		return nil
+	}
+	if _, err := r.env.client.Unlink(ctx, r.model, r.ids); err != nil {
+		return err
+	}
+	r.env.forget(r.model, r.ids, nil)
+	return nil
+}
+
+// Mapped follows a dotted field path, such as "partner_id.country_id.code",
+// and returns the values of the last field for every record, in order.
+// Intermediate relational fields are traversed as in Odoo: the records
+// reached at each step are deduplicated. When the last field is relational
+// the related ids are returned as int64; use MappedRecords for a recordset.
+func (r *Recordset) Mapped(ctx context.Context, path string) ([]any, error) {
+	parts := strings.Split(path, ".")
+	current, err := r.follow(ctx, parts[:len(parts)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+	info, err := current.env.fieldInfo(ctx, current.model, last)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, 0, current.Len())
+	for _, rec := range current.Records() {
+		v, err := rec.Value(ctx, last)
+		if err != nil {
+			return nil, err
+		}
+		if info.relational() {
+			for _, id := range relatedIDs(info, v) {
+				values = append(values, id)
+			}
+			continue
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// MappedRecords follows a dotted path of relational fields and returns the
+// union of the records reached.
+func (r *Recordset) MappedRecords(ctx context.Context, path string) (*Recordset, error) {
+	return r.follow(ctx, strings.Split(path, "."))
+}
+
+func (r *Recordset) follow(ctx context.Context, fields []string) (*Recordset, error) {
+	current := r
+	for _, field := range fields {
+		info, err := current.env.fieldInfo(ctx, current.model, field)
+		if err != nil {
+			return nil, err
+		}
+		if !info.relational() {
+			return nil, fmt.Errorf("field %s.%s is not relational", current.model, field)
+		}
+		var ids []int64
+		for _, rec := range current.Records() {
+			v, err := rec.Value(ctx, field)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, relatedIDs(info, v)...)
+		}
+		current = current.env.browse(info.Relation, ids)
+	}
+	return current, nil
+}
+
+// Filtered returns the records matching domain, keeping their order.
+func (r *Recordset) Filtered(ctx context.Context, domain odoorpc.Domain) (*Recordset, error) {
+	if r.IsEmpty() {
+		return r, nil
+	}
+	opts := odoorpc.Options{Context: map[string]any{"active_test": false}}
+	// Prefix the id leaf in flat form; nesting domains is invalid in Odoo.
+	filter := odoorpc.NewDomain().In("id", r.ids)
+	if len(domain) > 0 {
+		filter = append(append(odoorpc.Domain{"&"}, filter...), domain...)
+	}
+	ids, err := r.env.client.Search(ctx, r.model, filter, r.env.options(opts))
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		keep[id] = true
+	}
+	return r.subset(func(id int64) bool { return keep[id] }), nil
+}
+
+// FilteredFunc returns the records for which keep returns true.
+func (r *Recordset) FilteredFunc(keep func(rec *Recordset) (bool, error)) (*Recordset, error) {
+	var ids []int64
+	for _, rec := range r.Records() {
+		ok, err := keep(rec)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ids = append(ids, rec.ids[0])
+		}
+	}
+	return r.withIDs(ids), nil
+}
+
+// Sorted returns the records ordered server side by order, e.g.
+// "name desc, id".
+func (r *Recordset) Sorted(ctx context.Context, order string) (*Recordset, error) {
+	if r.IsEmpty() {
+		return r, nil
+	}
+	opts := odoorpc.Options{Order: order, Context: map[string]any{"active_test": false}}
+	ids, err := r.env.client.Search(ctx, r.model, odoorpc.NewDomain().In("id", r.ids), r.env.options(opts))
+	if err != nil {
+		return nil, err
+	}
+	return r.withIDs(ids), nil
+}
+
+// Union returns the records of r followed by those of others not already
+// present.
+func (r *Recordset) Union(others ...*Recordset) (*Recordset, error) {
+	ids := slices.Clone(r.ids)
+	for _, other := range others {
+		if other.model != r.model {
+			return nil, fmt.Errorf("%w: %s and %s", ErrModelMismatch, r.model, other.model)
+		}
+		ids = append(ids, other.ids...)
+	}
+	return r.withIDs(dedupe(ids)), nil
+}
+
+// Intersection returns the records of r also present in other.
+func (r *Recordset) Intersection(other *Recordset) (*Recordset, error) {
+	if other.model != r.model {
+		return nil, fmt.Errorf("%w: %s and %s", ErrModelMismatch, r.model, other.model)
+	}
+	return r.subset(func(id int64) bool { return slices.Contains(other.ids, id) }), nil
+}
+
+// Difference returns the records of r not present in other.
+func (r *Recordset) Difference(other *Recordset) (*Recordset, error) {
+	if other.model != r.model {
+		return nil, fmt.Errorf("%w: %s and %s", ErrModelMismatch, r.model, other.model)
+	}
+	return r.subset(func(id int64) bool { return !slices.Contains(other.ids, id) }), nil
+}
+
+func (r *Recordset) subset(keep func(id int64) bool) *Recordset {
+	var ids []int64
+	for _, id := range r.ids {
+		if keep(id) {
+			ids = append(ids, id)
+		}
+	}
+	return r.withIDs(ids)
+}
+
+// withIDs returns a recordset of the same model sharing the prefetch set.
+func (r *Recordset) withIDs(ids []int64) *Recordset {
+	prefetch := r.prefetch
+	for _, id := range ids {
+		if !slices.Contains(prefetch, id) {
+			prefetch = dedupe(append(slices.Clone(r.prefetch), ids...))
+			break
+		}
+	}
+	return &Recordset{env: r.env, model: r.model, ids: ids, prefetch: prefetch}
+}
@@ -0,0 +1,219 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// memClient is an in-memory odoorpc.Client over a few fixed tables.
+type memClient struct {
+	odoorpc.Client
+	tables map[string]map[int64]map[string]any
+	fields map[string]map[string]any
+	reads  int
+	// searches records the domains passed to Search.
+	searches []odoorpc.Domain
+}
+
+func newMemClient() *memClient {
+	return &memClient{
+		tables: map[string]map[int64]map[string]any{
+			"res.partner": {
+				1: {"name": "Acme", "country_id": []any{float64(10), "Spain"}, "active": true},
+				2: {"name": "Globex", "country_id": []any{float64(11), "France"}, "active": true},
+				3: {"name": "Initech", "country_id": []any{float64(10), "Spain"}, "active": false},
+				4: {"name": "Hooli", "country_id": false, "active": true},
+			},
+			"res.country": {
+				10: {"code": "ES"},
+				11: {"code": "FR"},
+			},
+		},
+		fields: map[string]map[string]any{
+			"res.partner": {
+				"name":       map[string]any{"type": "char"},
+				"active":     map[string]any{"type": "boolean"},
+				"country_id": map[string]any{"type": "many2one", "relation": "res.country"},
+			},
+			"res.country": {"code": map[string]any{"type": "char"}},
+		},
+	}
+}
+
+func (m *memClient) FieldsGet(ctx context.Context, model string, fields []string, opts odoorpc.Options) (map[string]any, error) {
+	return m.fields[model], nil
+}
+
+func (m *memClient) Read(ctx context.Context, model string, ids []int64, opts odoorpc.Options) ([]map[string]any, error) {
+	m.reads++
+	var res []map[string]any
+	for _, id := range ids {
+		rec := map[string]any{"id": float64(id)}
+		for _, f := range opts.Fields {
+			rec[f] = m.tables[model][id][f]
+		}
+		res = append(res, rec)
+	}
+	return res, nil
+}
+
+// Search understands domains made of ("id", "in", ids) and
+// ("active", "=", bool) conditions; order "name" sorts by name.
+func (m *memClient) Search(ctx context.Context, model string, domain odoorpc.Domain, opts odoorpc.Options) ([]int64, error) {
+	m.searches = append(m.searches, domain)
+	var ids []int64
+	for id := range m.tables[model] {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, item := range domain {
+		cond, ok := item.([]any)
+		if !ok {
+			continue
+		}
+		ids = slices.DeleteFunc(ids, func(id int64) bool {
+			switch cond[0] {
+			case "id":
+				for _, v := range cond[2].([]any) {
+					if v.(int64) == id {
+						return false
+					}
+				}
+				return true
+			case "active":
+				return m.tables[model][id]["active"] != cond[2]
+			}
+			return false
+		})
+	}
+	if opts.Order == "name" {
+		slices.SortFunc(ids, func(a, b int64) int {
+			return strings.Compare(m.tables[model][a]["name"].(string), m.tables[model][b]["name"].(string))
+		})
+	}
+	return ids, nil
+}
+
+func (m *memClient) Update(ctx context.Context, model string, ids []int64, values map[string]any) (bool, error) {
+	for _, id := range ids {
+		for k, v := range values {
+			m.tables[model][id][k] = v
+		}
+	}
+	return true, nil
+}
+
+func TestRecordsetPrefetchAndMapped(t *testing.T) {
+	client := newMemClient()
+	env := NewEnv(client)
+	ctx := context.Background()
+	partners := env.Model("res.partner").Browse(1, 2, 3, 4)
+
+	var names []any
+	for _, rec := range partners.Records() {
+		name, err := rec.Value(ctx, "name")
+		if err != nil {
+			t.Fatalf("Value: %v", err)
+		}
+		names = append(names, name)
+	}
+	if client.reads != 1 {
+		t.Fatalf("expected a single prefetching read, got %d", client.reads)
+	}
+	if !reflect.DeepEqual(names, []any{"Acme", "Globex", "Initech", "Hooli"}) {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	codes, err := partners.Mapped(ctx, "country_id.code")
+	if err != nil {
+		t.Fatalf("Mapped: %v", err)
+	}
+	if !reflect.DeepEqual(codes, []any{"ES", "FR"}) {
+		t.Fatalf("unexpected codes: %v", codes)
+	}
+	countries, err := partners.MappedRecords(ctx, "country_id")
+	if err != nil || !reflect.DeepEqual(countries.IDs(), []int64{10, 11}) {
+		t.Fatalf("MappedRecords: %v %v", countries, err)
+	}
+}
+
+func TestRecordsetFilteredSortedAndSets(t *testing.T) {
+	env := NewEnv(newMemClient())
+	ctx := context.Background()
+	model := env.Model("res.partner")
+	all := model.Browse(4, 3, 2, 1)
+
+	active, err := all.Filtered(ctx, odoorpc.NewDomain().Equals("active", true))
+	if err != nil || !reflect.DeepEqual(active.IDs(), []int64{4, 2, 1}) {
+		t.Fatalf("Filtered: %v %v", active, err)
+	}
+	sorted, err := active.Sorted(ctx, "name")
+	if err != nil || !reflect.DeepEqual(sorted.IDs(), []int64{1, 2, 4}) {
+		t.Fatalf("Sorted: %v %v", sorted, err)
+	}
+
+	union, _ := model.Browse(1, 2).Union(model.Browse(2, 3))
+	inter, _ := model.Browse(1, 2).Intersection(model.Browse(2, 3))
+	diff, _ := model.Browse(1, 2).Difference(model.Browse(2, 3))
+	if !reflect.DeepEqual(union.IDs(), []int64{1, 2, 3}) || !reflect.DeepEqual(inter.IDs(), []int64{2}) || !reflect.DeepEqual(diff.IDs(), []int64{1}) {
+		t.Fatalf("unexpected set operations: %v %v %v", union, inter, diff)
+	}
+	if _, err := union.Union(env.Model("res.country").Browse(10)); !errors.Is(err, ErrModelMismatch) {
+		t.Fatalf("expected ErrModelMismatch, got %v", err)
+	}
+	if err := union.EnsureOne(); err == nil {
+		t.Fatalf("expected EnsureOne error")
+	}
+}
+
+func TestRecordsetWriteInvalidatesCache(t *testing.T) {
+	env := NewEnv(newMemClient())
+	ctx := context.Background()
+	rec := env.Model("res.partner").Browse(1)
+	if v, _ := rec.Value(ctx, "name"); v != "Acme" {
+		t.Fatalf("unexpected name %v", v)
+	}
+	if err := rec.Write(ctx, map[string]any{"name": "Acme Corp"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if v, _ := rec.Value(ctx, "name"); v != "Acme Corp" {
+		t.Fatalf("stale cache after write: %v", v)
+	}
+}
+
+func TestRecordsetFilteredFlatDomain(t *testing.T) {
+	client := newMemClient()
+	env := NewEnv(client)
+	ctx := context.Background()
+	partners := env.Model("res.partner").Browse(1, 2)
+	idLeaf := []any{"id", "in", []any{int64(1), int64(2)}}
+
+	for _, tc := range []struct {
+		domain odoorpc.Domain
+		want   odoorpc.Domain
+	}{
+		{
+			odoorpc.NewDomain().Equals("active", true).Equals("name", "Acme"),
+			odoorpc.Domain{"&", idLeaf, []any{"active", "=", true}, []any{"name", "=", "Acme"}},
+		},
+		{
+			odoorpc.Domain{"|", []any{"active", "=", true}, []any{"name", "=", "Acme"}},
+			odoorpc.Domain{"&", idLeaf, "|", []any{"active", "=", true}, []any{"name", "=", "Acme"}},
+		},
+		{nil, odoorpc.Domain{idLeaf}},
+	} {
+		client.searches = nil
+		if _, err := partners.Filtered(ctx, tc.domain); err != nil {
+			t.Fatalf("Filtered: %v", err)
+		}
+		if len(client.searches) != 1 || !reflect.DeepEqual(client.searches[0], tc.want) {
+			t.Errorf("Filtered(%v) searched %#v", tc.domain, client.searches)
+		}
+	}
+}
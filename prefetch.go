@@ -0,0 +1,291 @@
+package odoorpc
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// DefaultPrefetchChunkSize is the number of records read per request when
+// PrefetchOptions.ChunkSize is zero.
+const DefaultPrefetchChunkSize = 1000
+
+// PrefetchOptions controls Prefetch.
+type PrefetchOptions struct {
+	// ChunkSize is the maximum number of ids per read request.
+	ChunkSize int
+}
+
+// Prefetch resolves the relational fields of records previously read from
+// model, such as the result of SearchRead, replacing their values in place.
+//
+// spec selects the relational fields to resolve and, through Fields, the
+// fields read on the related records; nesting specs resolves relations of
+// the related records as well, up to any depth. Every target model is read
+// once per level, in chunks, whatever the number of records and of fields
+// pointing to it, which then share the related records: many2one
+// values become a map and x2many values a slice of maps. Relational fields
+// given without Fields are reduced to their ids, and fields of records that
+// are not in spec are left untouched.
+//
+//	err := c.Prefetch(ctx, "account.move.line", lines, odoorpc.Spec{
+//		"product_id": {Fields: odoorpc.Spec{"default_code": {}, "uom_id": {}}},
+//		"account_id": {Fields: odoorpc.Spec{"code": {}}},
+//	}, odoorpc.PrefetchOptions{})
+func (c *RpcClient) Prefetch(ctx context.Context, model string, records []map[string]any, spec Spec, opts PrefetchOptions) error {
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = DefaultPrefetchChunkSize
+	}
+	return c.prefetch(ctx, model, records, spec, size)
+}
+
+// relationGroup gathers the fields of a level read from the same comodel
+// with the same context.
+type relationGroup struct {
+	model   string
+	context map[string]any
+	spec    Spec
+	names   []string
+	ids     []int64
+}
+
+func (c *RpcClient) prefetch(ctx context.Context, model string, recs []map[string]any, spec Spec, size int) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	meta, err := c.fieldsMeta(ctx, model)
+	if err != nil {
+		return err
+	}
+	var groups []*relationGroup
+	byKey := map[string]*relationGroup{}
+	for _, name := range slices.Sorted(maps.Keys(spec)) {
+		sub := spec[name]
+		info, ok := meta[name]
+		if !ok {
+			return fmt.Errorf("field %s.%s does not exist", model, name)
+		}
+		if !info.relational() {
+			continue
+		}
+		if len(sub.Fields) == 0 {
+			for _, rec := range recs {
+				if v, ok := rec[name]; ok {
+					rec[name] = relationIDsValue(info, v)
+				}
+			}
+			continue
+		}
+		key := info.Relation + fmt.Sprint(sub.Context)
+		g := byKey[key]
+		if g == nil {
+			g = &relationGroup{model: info.Relation, context: sub.Context}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.spec = mergeSpecs(g.spec, sub.Fields)
+		g.names = append(g.names, name)
+		for _, rec := range recs {
+			g.ids = append(g.ids, relationIDs(rec[name])...)
+		}
+	}
+	for _, g := range groups {
+		sc := c.withCallContext(g.context)
+		children, err := sc.readChunks(ctx, g.model, uniqueIDs(g.ids), specFields(g.spec), size)
+		if err != nil {
+			return err
+		}
+		if err := sc.prefetch(ctx, g.model, children, g.spec, size); err != nil {
+			return err
+		}
+		byID := make(map[int64]map[string]any, len(children))
+		for _, child := range children {
+			if id, ok := child["id"].(float64); ok {
+				byID[int64(id)] = child
+			}
+		}
+		for _, name := range g.names {
+			sub, info := spec[name], meta[name]
+			var rank map[int64]int
+			if sub.Order != "" && info.Type != "many2one" {
+				var related []int64
+				for _, rec := range recs {
+					related = append(related, relationIDs(rec[name])...)
+				}
+				if rank, err = sc.orderRank(ctx, g.model, uniqueIDs(related), sub.Order); err != nil {
+					return err
+				}
+			}
+			for _, rec := range recs {
+				if v, ok := rec[name]; ok {
+					rec[name] = stitchRelation(info, v, byID, rank, sub.Limit)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mergeSpecs returns the union of a and b. The specs of fields in both are
+// merged as well, the settings of a winning when both set them.
+func mergeSpecs(a, b Spec) Spec {
+	out := make(Spec, len(a)+len(b))
+	maps.Copy(out, a)
+	for name, fb := range b {
+		fa, ok := out[name]
+		if !ok {
+			out[name] = fb
+			continue
+		}
+		if len(fa.Fields) > 0 || len(fb.Fields) > 0 {
+			fa.Fields = mergeSpecs(fa.Fields, fb.Fields)
+		}
+		if fa.Limit == 0 {
+			fa.Limit = fb.Limit
+		}
+		if fa.Order == "" {
+			fa.Order = fb.Order
+		}
+		if len(fb.Context) > 0 {
+			fa.Context = mergeContext(fb.Context, fa.Context)
+		}
+		out[name] = fa
+	}
+	return out
+}
+
+// mergeContext returns the values of base overridden by those of top.
+func mergeContext(base, top map[string]any) map[string]any {
+	out := maps.Clone(base)
+	maps.Copy(out, top)
+	return out
+}
+
+// orderRank returns the position of each id when ids are sorted by order,
+// searching archived records too as read returns them.
+func (c *RpcClient) orderRank(ctx context.Context, model string, ids []int64, order string) (map[int64]int, error) {
+	opts := Options{Order: order, Context: map[string]any{"active_test": false}}
+	sorted, err := c.Search(ctx, model, NewDomain().In("id", ids), opts)
+	if err != nil {
+		return nil, err
+	}
+	rank := make(map[int64]int, len(sorted))
+	for i, id := range sorted {
+		rank[id] = i
+	}
+	return rank, nil
+}
+
+// readChunks reads ids in requests of at most size ids.
+func (c *RpcClient) readChunks(ctx context.Context, model string, ids []int64, fields []string, size int) ([]map[string]any, error) {
+	recs := make([]map[string]any, 0, len(ids))
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+		chunk, err := c.Read(ctx, model, ids[start:end], Options{Fields: fields})
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, chunk...)
+	}
+	return recs, nil
+}
+
+func specFields(spec Spec) []string {
+	fields := make([]string, 0, len(spec))
+	for name := range spec {
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+func (c *RpcClient) withCallContext(values map[string]any) *RpcClient {
+	if len(values) == 0 {
+		return c
+	}
+	return c.WithContext(values)
+}
+
+// relationIDs extracts ids from a many2one ([id, name]) or x2many value.
+func relationIDs(v any) []int64 {
+	list, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	if len(list) == 2 {
+		if _, isName := list[1].(string); isName {
+			// many2one [id, display_name]
+			list = list[:1]
+		}
+	}
+	ids := make([]int64, 0, len(list))
+	for _, item := range list {
+		if id, ok := item.(float64); ok {
+			ids = append(ids, int64(id))
+		}
+	}
+	return ids
+}
+
+func relationIDsValue(info fieldMeta, v any) any {
+	ids := relationIDs(v)
+	if info.Type == "many2one" {
+		if len(ids) == 0 {
+			return false
+		}
+		return ids[0]
+	}
+	return ids
+}
+
+// stitchRelation replaces a relational value by the related records. x2many
+// ids are sorted by rank, when given, before applying limit.
+func stitchRelation(info fieldMeta, v any, byID map[int64]map[string]any, rank map[int64]int, limit int) any {
+	ids := relationIDs(v)
+	if info.Type == "many2one" {
+		if len(ids) == 0 {
+			return false
+		}
+		if child, ok := byID[ids[0]]; ok {
+			return child
+		}
+		return map[string]any{"id": ids[0]}
+	}
+	if rank != nil {
+		slices.SortStableFunc(ids, func(a, b int64) int {
+			return cmp.Compare(rankOf(rank, a), rankOf(rank, b))
+		})
+	}
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	children := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		if child, ok := byID[id]; ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// rankOf returns the rank of id, placing unknown ids last.
+func rankOf(rank map[int64]int, id int64) int {
+	if r, ok := rank[id]; ok {
+		return r
+	}
+	return len(rank)
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package odoorpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestPrefetchReadsEachModelOnceInChunks(t *testing.T) {
+	fields := map[string]any{
+		"account.move.line": map[string]any{
+			"product_id": map[string]any{"type": "many2one", "relation": "product.product"},
+			"account_id": map[string]any{"type": "many2one", "relation": "account.account"},
+		},
+		"product.product": map[string]any{
+			"categ_id": map[string]any{"type": "many2one", "relation": "product.category"},
+		},
+		"product.category": map[string]any{
+			"name": map[string]any{"type": "char"},
+		},
+	}
+	reads := map[string][]int{}
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "fields_get":
+			return fields[call.Model], nil
+		case "read":
+			ids := call.Args[0].([]any)
+			reads[call.Model] = append(reads[call.Model], len(ids))
+			var recs []any
+			for _, id := range ids {
+				rec := map[string]any{"id": id}
+				switch call.Model {
+				case "product.product":
+					rec["categ_id"] = []any{100 + id.(float64), "Category"}
+				case "product.category":
+					rec["name"] = "Category"
+				}
+				recs = append(recs, rec)
+			}
+			return recs, nil
+		}
+		t.Errorf("unexpected call %s", call.Method)
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+
+	var lines []map[string]any
+	for i := 1; i <= 5; i++ {
+		lines = append(lines, map[string]any{
+			"id":         float64(i),
+			"product_id": []any{float64(i%3 + 1), "Product"},
+			"account_id": []any{float64(40), "Sales"},
+		})
+	}
+	lines[4]["product_id"] = false
+
+	spec := odoorpc.Spec{
+		"product_id": {Fields: odoorpc.Spec{"categ_id": {Fields: odoorpc.Spec{"name": {}}}}},
+		"account_id": {},
+	}
+	if err := c.Prefetch(context.Background(), "account.move.line", lines, spec, odoorpc.PrefetchOptions{ChunkSize: 2}); err != nil {
+		t.Fatalf("Prefetch: %v", err)
+	}
+
+	if got := reads["product.product"]; len(got) != 2 || got[0] != 2 || got[1] != 1 {
+		t.Errorf("products should be read once in chunks of 2, got %v", got)
+	}
+	if got := reads["product.category"]; len(got) != 2 {
+		t.Errorf("categories should be read once in chunks of 2, got %v", got)
+	}
+	if _, ok := reads["account.account"]; ok {
+		t.Errorf("fields without sub fields must not be read")
+	}
+	product := lines[0]["product_id"].(map[string]any)
+	categ := product["categ_id"].(map[string]any)
+	if product["id"] != float64(2) || categ["name"] != "Category" {
+		t.Errorf("unexpected nested product %v", product)
+	}
+	if lines[0]["account_id"] != int64(40) {
+		t.Errorf("account should be reduced to its id, got %#v", lines[0]["account_id"])
+	}
+	if lines[4]["product_id"] != false {
+		t.Errorf("empty many2one should stay false, got %#v", lines[4]["product_id"])
+	}
+}
+
+func TestPrefetchGroupsFieldsByComodel(t *testing.T) {
+	fields := map[string]any{
+		"sale.order": map[string]any{
+			"partner_id":          map[string]any{"type": "many2one", "relation": "res.partner"},
+			"partner_shipping_id": map[string]any{"type": "many2one", "relation": "res.partner"},
+		},
+		"res.partner": map[string]any{
+			"name": map[string]any{"type": "char"},
+			"city": map[string]any{"type": "char"},
+		},
+	}
+	var reads []fakeCall
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "fields_get":
+			return fields[call.Model], nil
+		case "read":
+			reads = append(reads, call)
+			var recs []any
+			for _, id := range call.Args[0].([]any) {
+				recs = append(recs, map[string]any{"id": id, "name": "Partner", "city": "Sevilla"})
+			}
+			return recs, nil
+		}
+		t.Errorf("unexpected call %s", call.Method)
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+
+	orders := []map[string]any{
+		{"id": float64(1), "partner_id": []any{float64(3), "Acme"}, "partner_shipping_id": []any{float64(4), "Acme, Depot"}},
+		{"id": float64(2), "partner_id": []any{float64(4), "Acme, Depot"}, "partner_shipping_id": false},
+	}
+	spec := odoorpc.Spec{
+		"partner_id":          {Fields: odoorpc.Spec{"name": {}}},
+		"partner_shipping_id": {Fields: odoorpc.Spec{"city": {}}},
+	}
+	if err := c.Prefetch(context.Background(), "sale.order", orders, spec, odoorpc.PrefetchOptions{}); err != nil {
+		t.Fatalf("Prefetch: %v", err)
+	}
+	if len(reads) != 1 {
+		t.Fatalf("res.partner should be read once, got %d reads", len(reads))
+	}
+	if ids := reads[0].Args[0].([]any); len(ids) != 2 {
+		t.Errorf("partner ids should be deduplicated across fields, got %v", ids)
+	}
+	if got := orders[0]["partner_shipping_id"].(map[string]any); got["city"] != "Sevilla" {
+		t.Errorf("unexpected shipping partner %v", got)
+	}
+	if got := orders[1]["partner_id"].(map[string]any); got["name"] != "Partner" {
+		t.Errorf("unexpected partner %v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrWebSessionRequired is returned by operations served by web routes
+// (reports, attachments downloads) when the client cannot open a web
+// session: it was authenticated with an API key, which Odoo only accepts
+// over RPC. Use SessionAuthenticate with the user password instead.
+var ErrWebSessionRequired = errors.New("a web session is required")
+
+// ErrWkhtmltopdf is wrapped by report errors caused by wkhtmltopdf missing
+// or failing on the server.
+var ErrWkhtmltopdf = errors.New("wkhtmltopdf is not available on the server")
+
+// ReportFormat is the output format of a rendered report.
+type ReportFormat string
+
+const (
+	ReportPDF  ReportFormat = "pdf"
+	ReportHTML ReportFormat = "html"
+	ReportText ReportFormat = "text"
+)
+
+// Report describes an ir.actions.report.
+type Report struct {
+	ID   int64
+	Name string
+	// ReportName is the technical name used in report URLs, e.g.
+	// "account.report_invoice".
+	ReportName string
+	// Model is the model of the printed records.
+	Model string
+	// Type is the report type: "qweb-pdf", "qweb-html" or "qweb-text".
+	Type string
+}
+
+// ReportError is returned when the server fails to render a report.
+type ReportError struct {
+	Report string
+	// Name is the Odoo exception class, when reported.
+	Name    string
+	Message string
+	Err     error
+}
+
+func (e *ReportError) Error() string {
+	return fmt.Sprintf("report %s: %s", e.Report, e.Message)
+}
+
+func (e *ReportError) Unwrap() error { return e.Err }
+
+// Report returns the ir.actions.report identified by ref, either its
+// external id ("account.account_invoices") or its report name
+// ("account.report_invoice").
+func (c *RpcClient) Report(ctx context.Context, ref string) (Report, error) {
+	fields := []string{"name", "report_name", "model", "report_type"}
+	var recs []map[string]any
+	xref, err := c.ResolveXMLID(ctx, ref)
+	switch {
+	case err == nil:
+		if xref.Model != "ir.actions.report" {
+			return Report{}, fmt.Errorf("%s is a %s, not a report", ref, xref.Model)
+		}
+		recs, err = c.Read(ctx, "ir.actions.report", []int64{xref.ID}, Options{Fields: fields})
+	case errors.Is(err, ErrXMLIDNotFound) || strings.Count(ref, ".") != 1:
+		recs, err = c.SearchRead(ctx, "ir.actions.report", NewDomain().Equals("report_name", ref), Options{Fields: fields, Limit: 1})
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	if len(recs) == 0 {
+		return Report{}, fmt.Errorf("report %s not found", ref)
+	}
+	rec := recs[0]
+	id, _ := rec["id"].(float64)
+	return Report{
+		ID:         int64(id),
+		Name:       stringValue(rec["name"]),
+		ReportName: stringValue(rec["report_name"]),
+		Model:      stringValue(rec["model"]),
+		Type:       stringValue(rec["report_type"]),
+	}, nil
+}
+
+// RenderReport renders the report identified by ref (see Report) for the
+// records ids and streams the output to w. It returns the number of bytes
+// written.
+//
+// Reports are rendered through the web routes used by the browser, so the
+// client needs a web session: either it logged in with SessionAuthenticate,
+// or one is opened with its password on first use, and again when it
+// expires. Rendering errors are
+// returned as *ReportError, wrapping ErrWkhtmltopdf when the server cannot
+// produce PDFs.
+func (c *RpcClient) RenderReport(ctx context.Context, ref string, ids []int64, format ReportFormat, w io.Writer) (int64, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("report %s: no records to print", ref)
+	}
+	report, err := c.Report(ctx, ref)
+	if err != nil {
+		return 0, err
+	}
+	return c.renderReport(ctx, report.ReportName, ids, nil, format, w)
+}
+
+// renderReport renders a report by name. data is the dictionary of values
+// passed to reports printed from wizards, sent as the "options" parameter
+// as the web client does; ids may then be empty.
+func (c *RpcClient) renderReport(ctx context.Context, reportName string, ids []int64, data map[string]any, format ReportFormat, w io.Writer) (int64, error) {
+	if err := c.ensureWebSession(ctx); err != nil {
+		return 0, err
+	}
+
+	path := "/report/" + string(format) + "/" + reportName
+	if len(ids) > 0 {
+		docIDs := make([]string, len(ids))
+		for i, id := range ids {
+			docIDs[i] = strconv.FormatInt(id, 10)
+		}
+		path += "/" + strings.Join(docIDs, ",")
+	}
+	params := url.Values{}
+	if len(data) > 0 {
+		options, err := json.Marshal(data)
+		if err != nil {
+			return 0, err
+		}
+		params.Set("options", string(options))
+	}
+	if len(c.context) > 0 {
+		odooContext, err := json.Marshal(c.context)
+		if err != nil {
+			return 0, err
+		}
+		params.Set("context", string(odooContext))
+	}
+
+	// GET routes need no CSRF token, unlike POST /report/download.
+	target := c.rpc.BaseURL() + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	resp, err := c.getWebRoute(ctx, target)
+	if err == nil && loginPage(resp) && !c.session {
+		// The web session expired: open a new one and retry once.
+		resp.Body.Close()
+		c.meta.mu.Lock()
+		c.meta.webSession = false
+		c.meta.mu.Unlock()
+		if err := c.ensureWebSession(ctx); err != nil {
+			return 0, err
+		}
+		resp, err = c.getWebRoute(ctx, target)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("report request error: %w", err)
+	}
+	defer resp.Body.Close()
+	if loginPage(resp) {
+		return 0, fmt.Errorf("%w: the web session expired", ErrWebSessionRequired)
+	}
+
+	// Rendering errors are answered as HTML error pages.
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode != http.StatusOK || (format != ReportHTML && strings.HasPrefix(contentType, "text/html")) {
+		return 0, reportError(reportName, resp)
+	}
+	return io.Copy(w, resp.Body)
+}
+
+func (c *RpcClient) getWebRoute(ctx context.Context, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.rpc.HTTPClient().Do(req)
+}
+
+// loginPage reports whether resp is, or redirects to, the login page web
+// routes answer when the session expired.
+func loginPage(resp *http.Response) bool {
+	if resp.Request != nil && resp.Request.URL.Path == "/web/login" {
+		return true
+	}
+	loc, err := resp.Location()
+	return err == nil && loc.Path == "/web/login"
+}
+
+// reportError decodes the error answered by a report route: an error page,
+// or the JSON serialized exception some versions embed in it.
+func reportError(name string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	rerr := &ReportError{Report: name}
+	var payload struct {
+		Message string `json:"message"`
+		Data    struct {
+			Name    string `json:"name"`
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if json.Unmarshal([]byte(html.UnescapeString(string(body))), &payload) == nil && payload.Data.Message != "" {
+		rerr.Name = payload.Data.Name
+		rerr.Message = payload.Data.Message
+	} else {
+		rerr.Message = strings.Join(strings.Fields(html.UnescapeString(stripTags(string(body)))), " ")
+		if rerr.Message == "" {
+			rerr.Message = resp.Status
+		}
+	}
+	if strings.Contains(strings.ToLower(rerr.Message), "wkhtmltopdf") {
+		rerr.Err = ErrWkhtmltopdf
+	}
+	return rerr
+}
+
+// ensureWebSession opens a web session for clients authenticated over RPC,
+// sharing the cookie jar with the client.
+func (c *RpcClient) ensureWebSession(ctx context.Context) error {
+	if c.session {
+		return nil
+	}
+	c.meta.mu.Lock()
+	open := c.meta.webSession
+	c.meta.mu.Unlock()
+	if open {
+		return nil
+	}
+	if c.creds == nil || c.login == "" {
+		return fmt.Errorf("%w: client is not authenticated", ErrWebSessionRequired)
+	}
+	secret, err := c.secret(ctx)
+	if err != nil {
+		return err
+	}
+	web := *c
+	if _, err := web.SessionAuthenticate(ctx, c.login, secret, c.db, nil); err != nil {
+		if LooksLikeAPIKey(secret) {
+			return fmt.Errorf("%w: API keys cannot open web sessions", ErrWebSessionRequired)
+		}
+		return fmt.Errorf("%w: %v", ErrWebSessionRequired, err)
+	}
+	c.meta.mu.Lock()
+	c.meta.webSession = true
+	c.meta.mu.Unlock()
+	return nil
+}
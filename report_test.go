@@ -0,0 +1,135 @@
+package odoorpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func reportServer(t *testing.T) *fakeOdoo {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Method == "login":
+			return 2, nil
+		case call.Model == "ir.model.data":
+			return []any{map[string]any{"module": "account", "name": "account_invoices", "model": "ir.actions.report", "res_id": 5}}, nil
+		case call.Model == "ir.actions.report":
+			return []any{map[string]any{"id": 5, "name": "Invoices", "report_name": "account.report_invoice", "model": "account.move", "report_type": "qweb-pdf"}}, nil
+		}
+		return nil, nil
+	})
+	// Each web session gets a new id: "web", "web2", "web3"...
+	sessions := 0
+	srv.Mux.HandleFunc("/web/session/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		sessions++
+		id := "web"
+		if sessions > 1 {
+			id = fmt.Sprint("web", sessions)
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session_id", Value: id, Path: "/"})
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": map[string]any{"uid": 2}})
+	})
+	return srv
+}
+
+func TestRenderReportStreamsPDF(t *testing.T) {
+	srv := reportServer(t)
+	srv.Mux.HandleFunc("/report/pdf/account.report_invoice/7,8", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session_id"); err != nil || c.Value != "web" {
+			t.Errorf("report request without web session")
+		}
+		if r.Method != http.MethodGet {
+			// POSTs to http routes need a CSRF token.
+			http.Error(w, "Session expired (invalid CSRF token)", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.Authenticate(ctx, "admin", "admin", "odoo"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	n, err := c.RenderReport(ctx, "account.account_invoices", []int64{7, 8}, odoorpc.ReportPDF, &buf)
+	if err != nil {
+		t.Fatalf("RenderReport: %v", err)
+	}
+	if n != 8 || buf.String() != "%PDF-1.7" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestRenderReportReportsWkhtmltopdfErrors(t *testing.T) {
+	srv := reportServer(t)
+	srv.Mux.HandleFunc("/report/pdf/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("<!doctype html>\n<title>400 Bad Request</title>\n<h1>Bad Request</h1>\n" +
+			"<p>Unable to find Wkhtmltopdf on this system. The PDF can not be created.</p>\n"))
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.Authenticate(ctx, "admin", "admin", "odoo"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.RenderReport(ctx, "account.account_invoices", []int64{7}, odoorpc.ReportPDF, &bytes.Buffer{})
+	var rerr *odoorpc.ReportError
+	if !errors.As(err, &rerr) || !errors.Is(err, odoorpc.ErrWkhtmltopdf) {
+		t.Fatalf("expected a wkhtmltopdf ReportError, got %v", err)
+	}
+	if !strings.Contains(rerr.Message, "Unable to find Wkhtmltopdf") {
+		t.Errorf("unexpected message %q", rerr.Message)
+	}
+}
+
+func TestRenderReportReopensExpiredWebSession(t *testing.T) {
+	srv := reportServer(t)
+	srv.Mux.HandleFunc("/web/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<form class="oe_login_form"></form>`))
+	})
+	valid := "web"
+	srv.Mux.HandleFunc("/report/pdf/account.report_invoice/7", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session_id"); err != nil || c.Value != valid {
+			http.Redirect(w, r, "/web/login?redirect="+r.URL.Path, http.StatusSeeOther)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.Authenticate(ctx, "admin", "admin", "odoo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RenderReport(ctx, "account.account_invoices", []int64{7}, odoorpc.ReportPDF, io.Discard); err != nil {
+		t.Fatalf("RenderReport: %v", err)
+	}
+
+	// The server drops the session: the next report opens a new one.
+	valid = "web2"
+	var buf bytes.Buffer
+	if _, err := c.RenderReport(ctx, "account.account_invoices", []int64{7}, odoorpc.ReportPDF, &buf); err != nil {
+		t.Fatalf("RenderReport after expiry: %v", err)
+	}
+	if buf.String() != "%PDF-1.7" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	// A session that cannot be reopened is reported, not returned as a PDF.
+	valid = "none"
+	_, err := c.RenderReport(ctx, "account.account_invoices", []int64{7}, odoorpc.ReportPDF, io.Discard)
+	if !errors.Is(err, odoorpc.ErrWebSessionRequired) {
+		t.Errorf("expected ErrWebSessionRequired after one retry, got %v", err)
+	}
+}
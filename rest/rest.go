@@ -0,0 +1,503 @@
+// Package rest exposes Odoo models as JSON resources over HTTP, for
+// clients that should not speak execute_kw:
+//
+//	GET    /models/{model}        search_read; query: domain, fields, limit, offset, order, {field}=value
+//	POST   /models/{model}        create; body: field values
+//	GET    /models/{model}/{id}   read; query: fields
+//	PATCH  /models/{model}/{id}   write; body: field values
+//	DELETE /models/{model}/{id}   unlink
+//
+// Only the models and fields listed in Options.Models are exposed:
+//
+//	gw := rest.NewGateway(client, rest.Options{Models: map[string]rest.Model{
+//		"res.partner": {Fields: []string{"name", "email", "is_company"}},
+//		"product.product": {Fields: []string{"name", "default_code"}, ReadOnly: true},
+//	}})
+//	http.Handle("/api/", http.StripPrefix("/api", gw))
+//
+// Relational fields are written with ids only, never with commands that
+// would create or modify related records.
+//
+// Odoo errors are mapped to HTTP statuses: access errors to 403, missing
+// records to 404, validation and user errors to 422 and other server
+// errors to 502.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// DefaultLimit and MaxLimit bound listings when Options.DefaultLimit and
+// Options.MaxLimit are zero.
+const (
+	DefaultLimit = 80
+	MaxLimit     = 1000
+)
+
+// Model is the exposure policy of a model.
+type Model struct {
+	// Fields lists the fields that can be read, filtered, sorted and
+	// written. Empty exposes every field of the model.
+	Fields []string
+	// ReadOnly rejects create, write and unlink.
+	ReadOnly bool
+}
+
+func (m Model) allows(field string) bool {
+	return len(m.Fields) == 0 || field == "id" || slices.Contains(m.Fields, field)
+}
+
+// Options configures a Gateway.
+type Options struct {
+	// Models maps the exposed model names to their policy.
+	Models       map[string]Model
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Gateway is an http.Handler serving the exposed models.
+type Gateway struct {
+	client odoorpc.Client
+	opts   Options
+	mux    *http.ServeMux
+
+	mu sync.Mutex
+	// types caches the field types of each model, used to convert filters.
+	types map[string]map[string]string
+}
+
+// NewGateway returns a Gateway calling Odoo through client.
+func NewGateway(client odoorpc.Client, opts Options) *Gateway {
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = DefaultLimit
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = MaxLimit
+	}
+	g := &Gateway{client: client, opts: opts, mux: http.NewServeMux(), types: map[string]map[string]string{}}
+	g.mux.HandleFunc("GET /models/{model}", g.list)
+	g.mux.HandleFunc("POST /models/{model}", g.create)
+	g.mux.HandleFunc("GET /models/{model}/{id}", g.read)
+	g.mux.HandleFunc("PATCH /models/{model}/{id}", g.write)
+	g.mux.HandleFunc("DELETE /models/{model}/{id}", g.unlink)
+	return g
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// httpError is an error answered with a given status.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+func errorf(status int, format string, args ...any) error {
+	return &httpError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+// statusOf maps an error to its HTTP status.
+func statusOf(err error) int {
+	var he *httpError
+	var mc *odoorpc.MultiCompanyError
+	switch {
+	case errors.As(err, &he):
+		return he.status
+	case odoorpc.IsAccessDenied(err):
+		return http.StatusUnauthorized
+	case odoorpc.IsAccessError(err), errors.As(err, &mc):
+		return http.StatusForbidden
+	case odoorpc.IsMissingError(err):
+		return http.StatusNotFound
+	case odoorpc.IsUserError(err):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, odoorpc.ErrAuthenticationFailed):
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadGateway
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := statusOf(err)
+	body := map[string]any{"message": err.Error()}
+	if rpcErr, ok := odoorpc.OdooError(err); ok {
+		body["type"] = rpcErr.Data.Name
+		if rpcErr.Data.Message != "" {
+			body["message"] = rpcErr.Data.Message
+		}
+	} else if status == http.StatusBadGateway {
+		// Transport errors may reveal internal addresses.
+		body["message"] = "upstream error"
+	}
+	writeJSON(w, status, map[string]any{"error": body})
+}
+
+// model returns the policy of the requested model.
+func (g *Gateway) model(r *http.Request) (string, Model, error) {
+	name := r.PathValue("model")
+	m, ok := g.opts.Models[name]
+	if !ok {
+		return "", Model{}, errorf(http.StatusNotFound, "unknown model %q", name)
+	}
+	return name, m, nil
+}
+
+func recordID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errorf(http.StatusBadRequest, "invalid id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// fields returns the requested fields, or the exposed ones.
+func fields(r *http.Request, m Model) ([]string, error) {
+	param := r.URL.Query().Get("fields")
+	if param == "" {
+		return m.Fields, nil
+	}
+	fs := strings.Split(param, ",")
+	for _, f := range fs {
+		if !m.allows(f) {
+			return nil, errorf(http.StatusBadRequest, "field %q is not exposed", f)
+		}
+	}
+	return fs, nil
+}
+
+// reserved query parameters; the others filter by field equality.
+var reserved = []string{"domain", "fields", "limit", "offset", "order"}
+
+func (g *Gateway) listOptions(r *http.Request, name string, m Model) (odoorpc.Domain, odoorpc.Options, error) {
+	q := r.URL.Query()
+	var opts odoorpc.Options
+	var err error
+	if opts.Fields, err = fields(r, m); err != nil {
+		return nil, opts, err
+	}
+
+	domain := odoorpc.NewDomain()
+	if s := q.Get("domain"); s != "" {
+		if domain, err = odoorpc.ParseDomain(s); err != nil {
+			return nil, opts, errorf(http.StatusBadRequest, "%v", err)
+		}
+		if err := checkDomain(domain, m); err != nil {
+			return nil, opts, err
+		}
+	}
+	for key, values := range q {
+		if slices.Contains(reserved, key) {
+			continue
+		}
+		if !m.allows(key) {
+			return nil, opts, errorf(http.StatusBadRequest, "field %q is not exposed", key)
+		}
+		types, err := g.fieldTypes(r.Context(), name)
+		if err != nil {
+			return nil, opts, err
+		}
+		for _, v := range values {
+			value, err := queryValue(types[key], v)
+			if err != nil {
+				return nil, opts, errorf(http.StatusBadRequest, "invalid value %q for %s: %v", v, key, err)
+			}
+			domain = domain.Equals(key, value)
+		}
+	}
+
+	opts.Limit = g.opts.DefaultLimit
+	if s := q.Get("limit"); s != "" {
+		if opts.Limit, err = strconv.Atoi(s); err != nil || opts.Limit <= 0 {
+			return nil, opts, errorf(http.StatusBadRequest, "invalid limit %q", s)
+		}
+	}
+	opts.Limit = min(opts.Limit, g.opts.MaxLimit)
+	if s := q.Get("offset"); s != "" {
+		if opts.Offset, err = strconv.Atoi(s); err != nil || opts.Offset < 0 {
+			return nil, opts, errorf(http.StatusBadRequest, "invalid offset %q", s)
+		}
+	}
+	if s := q.Get("order"); s != "" {
+		for _, term := range strings.Split(s, ",") {
+			parts := strings.Fields(term)
+			if len(parts) == 0 || len(parts) > 2 || !m.allows(parts[0]) ||
+				(len(parts) == 2 && !strings.EqualFold(parts[1], "asc") && !strings.EqualFold(parts[1], "desc")) {
+				return nil, opts, errorf(http.StatusBadRequest, "invalid order %q", s)
+			}
+		}
+		opts.Order = s
+	}
+	return domain, opts, nil
+}
+
+// fieldTypes returns the field types of model, loading them once.
+func (g *Gateway) fieldTypes(ctx context.Context, model string) (map[string]string, error) {
+	g.mu.Lock()
+	types, ok := g.types[model]
+	g.mu.Unlock()
+	if ok {
+		return types, nil
+	}
+	res, err := g.client.FieldsGet(ctx, model, nil, odoorpc.Options{})
+	if err != nil {
+		return nil, err
+	}
+	types = make(map[string]string, len(res))
+	for name, raw := range res {
+		attrs, _ := raw.(map[string]any)
+		types[name], _ = attrs["type"].(string)
+	}
+	g.mu.Lock()
+	g.types[model] = types
+	g.mu.Unlock()
+	return types, nil
+}
+
+// queryValue converts a filter parameter according to the type of its
+// field, so that ?is_company=true and ?country_id=68 match while ?ref=123
+// stays a string. "false" matches empty values of non boolean fields too,
+// except text fields.
+func queryValue(fieldType, s string) (any, error) {
+	switch fieldType {
+	case "boolean":
+		return strconv.ParseBool(s)
+	case "integer", "many2one", "one2many", "many2many", "many2one_reference":
+		if s == "false" {
+			return false, nil
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case "float", "monetary":
+		if s == "false" {
+			return false, nil
+		}
+		return strconv.ParseFloat(s, 64)
+	case "date", "datetime", "selection":
+		if s == "false" {
+			return false, nil
+		}
+	}
+	return s, nil
+}
+
+// checkDomain rejects conditions on fields that are not exposed, including
+// paths traversing relations unless the full path is listed. Conditions of
+// the subdomains of "any" and "not any" are paths from their field.
+func checkDomain(d odoorpc.Domain, m Model) error {
+	var walk func(items []any, prefix string) error
+	walk = func(items []any, prefix string) error {
+		for _, item := range items {
+			leaf, ok := item.([]any)
+			if !ok {
+				continue
+			}
+			if field, ok := leaf[0].(string); ok && len(leaf) == 3 {
+				path := prefix + field
+				if !m.allows(path) {
+					return errorf(http.StatusBadRequest, "field %q is not exposed", path)
+				}
+				if op, _ := leaf[1].(string); op == "any" || op == "not any" {
+					sub, ok := leaf[2].([]any)
+					if !ok {
+						return errorf(http.StatusBadRequest, "operator %q on %q expects a domain", op, path)
+					}
+					if err := walk(sub, path+"."); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := walk(leaf, prefix); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(d, "")
+}
+
+// decodeValues decodes the body of create and write requests. Relational
+// fields only accept ids: command lists such as [0, 0, {...}] would create
+// or modify comodel records bypassing their policy.
+func (g *Gateway) decodeValues(w http.ResponseWriter, r *http.Request, name string, m Model) (map[string]any, error) {
+	var values map[string]any
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return nil, errorf(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+	for f := range values {
+		if f == "id" || !m.allows(f) {
+			return nil, errorf(http.StatusBadRequest, "field %q is not writable", f)
+		}
+	}
+	types, err := g.fieldTypes(r.Context(), name)
+	if err != nil {
+		return nil, err
+	}
+	for f, v := range values {
+		if !relationValue(types[f], v) {
+			return nil, errorf(http.StatusBadRequest, "field %q only accepts record ids", f)
+		}
+	}
+	return values, nil
+}
+
+// relationValue reports whether v is a valid value for a field of
+// fieldType: an id or false for many2one fields and a list of ids for
+// x2many fields. Other field types accept any value.
+func relationValue(fieldType string, v any) bool {
+	switch fieldType {
+	case "many2one":
+		_, isID := v.(json.Number)
+		return isID || v == false
+	case "one2many", "many2many":
+		list, ok := v.([]any)
+		if !ok {
+			return false
+		}
+		for _, item := range list {
+			if _, isID := item.(json.Number); !isID {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+func (g *Gateway) list(w http.ResponseWriter, r *http.Request) {
+	name, m, err := g.model(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	domain, opts, err := g.listOptions(r, name, m)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	recs, err := g.client.SearchRead(r.Context(), name, domain, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if recs == nil {
+		recs = []map[string]any{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"records": recs, "limit": opts.Limit, "offset": opts.Offset})
+}
+
+func (g *Gateway) read(w http.ResponseWriter, r *http.Request) {
+	name, m, err := g.model(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	id, err := recordID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	fs, err := fields(r, m)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	recs, err := g.client.Read(r.Context(), name, []int64{id}, odoorpc.Options{Fields: fs})
+	if err == nil && len(recs) == 0 {
+		err = errorf(http.StatusNotFound, "%s(%d) does not exist", name, id)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recs[0])
+}
+
+func (g *Gateway) writable(r *http.Request) (string, Model, error) {
+	name, m, err := g.model(r)
+	if err == nil && m.ReadOnly {
+		err = errorf(http.StatusMethodNotAllowed, "model %q is read only", name)
+	}
+	return name, m, err
+}
+
+func (g *Gateway) create(w http.ResponseWriter, r *http.Request) {
+	name, m, err := g.writable(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	values, err := g.decodeValues(w, r, name, m)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	id, err := g.client.Create(r.Context(), name, values)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", strings.TrimSuffix(r.URL.Path, "/"), id))
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
+}
+
+func (g *Gateway) write(w http.ResponseWriter, r *http.Request) {
+	name, m, err := g.writable(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	id, err := recordID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	values, err := g.decodeValues(w, r, name, m)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, err := g.client.Update(r.Context(), name, []int64{id}, values); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g *Gateway) unlink(w http.ResponseWriter, r *http.Request) {
+	name, _, err := g.writable(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	id, err := recordID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, err := g.client.Unlink(r.Context(), name, []int64{id}); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,159 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/jsonrpc"
+	"github.com/Guadalsistema/odoorpc/rest"
+)
+
+type fakeClient struct {
+	odoorpc.Client
+	domain  odoorpc.Domain
+	opts    odoorpc.Options
+	written map[string]any
+}
+
+func odooError(name, msg string) error {
+	return &jsonrpc.Error{Code: 200, Message: "Odoo Server Error", Data: jsonrpc.ErrorData{Name: name, Message: msg}}
+}
+
+func (c *fakeClient) SearchRead(ctx context.Context, model string, domain odoorpc.Domain, opts odoorpc.Options) ([]map[string]any, error) {
+	c.domain, c.opts = domain, opts
+	return []map[string]any{{"id": 1, "name": "Acme"}}, nil
+}
+
+func (c *fakeClient) FieldsGet(ctx context.Context, model string, fields []string, opts odoorpc.Options) (map[string]any, error) {
+	return map[string]any{
+		"id":         map[string]any{"type": "integer"},
+		"name":       map[string]any{"type": "char"},
+		"ref":        map[string]any{"type": "char"},
+		"is_company": map[string]any{"type": "boolean"},
+		"parent_id":  map[string]any{"type": "many2one"},
+		"child_ids":  map[string]any{"type": "one2many"},
+	}, nil
+}
+
+func (c *fakeClient) Read(ctx context.Context, model string, ids []int64, opts odoorpc.Options) ([]map[string]any, error) {
+	if ids[0] == 404 {
+		return nil, odooError(odoorpc.ExceptionMissingError, "Record does not exist or has been deleted.")
+	}
+	return []map[string]any{{"id": ids[0], "name": "Acme"}}, nil
+}
+
+func (c *fakeClient) Create(ctx context.Context, model string, values map[string]any) (int64, error) {
+	c.written = values
+	return 9, nil
+}
+
+func (c *fakeClient) Update(ctx context.Context, model string, ids []int64, values map[string]any) (bool, error) {
+	if _, ok := values["email"]; ok {
+		return false, odooError(odoorpc.ExceptionValidationError, "Invalid email")
+	}
+	return true, nil
+}
+
+func (c *fakeClient) Unlink(ctx context.Context, model string, ids []int64) (bool, error) {
+	return false, odooError(odoorpc.ExceptionAccessError, "You are not allowed to delete partners")
+}
+
+func do(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func newGateway(client odoorpc.Client) http.Handler {
+	return rest.NewGateway(client, rest.Options{Models: map[string]rest.Model{
+		"res.partner":     {Fields: []string{"name", "email", "is_company", "ref", "parent_id", "parent_id.name", "child_ids"}},
+		"product.product": {ReadOnly: true},
+	}})
+}
+
+func TestListTranslatesQuery(t *testing.T) {
+	client := &fakeClient{}
+	gw := newGateway(client)
+	w := do(gw, "GET", "/models/res.partner?domain="+
+		"[('name','ilike','ac')]&is_company=true&fields=name&limit=5&offset=10&order=name+desc", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	want := odoorpc.Domain{[]any{"name", "ilike", "ac"}, []any{"is_company", "=", true}}
+	if !reflect.DeepEqual(client.domain, want) {
+		t.Errorf("domain = %v", client.domain)
+	}
+	if o := client.opts; o.Limit != 5 || o.Offset != 10 || o.Order != "name desc" || !reflect.DeepEqual(o.Fields, []string{"name"}) {
+		t.Errorf("options = %+v", o)
+	}
+	var body struct{ Records []map[string]any }
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Records) != 1 {
+		t.Errorf("unexpected body %s", w.Body)
+	}
+}
+
+func TestGatewayEnforcesAllowlistAndMapsErrors(t *testing.T) {
+	client := &fakeClient{}
+	gw := newGateway(client)
+	cases := []struct {
+		method, target, body string
+		status               int
+	}{
+		{"GET", "/models/res.users", "", http.StatusNotFound},
+		{"GET", "/models/res.partner?fields=password", "", http.StatusBadRequest},
+		{"GET", "/models/res.partner?domain=[('user_ids.password','=','x')]", "", http.StatusBadRequest},
+		{"GET", "/models/res.partner?domain=[('parent_id','any',[('user_ids.password','=','x')])]", "", http.StatusBadRequest},
+		{"GET", "/models/res.partner?domain=[('parent_id','not+any',[('name','=','x'),('email','=','y')])]", "", http.StatusBadRequest},
+		{"GET", "/models/res.partner?domain=[('parent_id','any',[('name','=','x')])]", "", http.StatusOK},
+		{"GET", "/models/res.partner?is_company=maybe", "", http.StatusBadRequest},
+		{"GET", "/models/res.partner?order=password", "", http.StatusBadRequest},
+		{"GET", "/models/res.partner/404", "", http.StatusNotFound},
+		{"GET", "/models/res.partner/abc", "", http.StatusBadRequest},
+		{"POST", "/models/res.partner", `{"name": "Acme", "credit_limit": 1}`, http.StatusBadRequest},
+		{"POST", "/models/product.product", `{"name": "Desk"}`, http.StatusMethodNotAllowed},
+		{"PATCH", "/models/res.partner/1", `{"email": "nope"}`, http.StatusUnprocessableEntity},
+		{"PATCH", "/models/res.partner/1", `{"name": "Acme SA"}`, http.StatusNoContent},
+		{"POST", "/models/res.partner", `{"name": "Acme", "child_ids": [[0, 0, {"name": "Admin", "user_ids": [[0, 0, {"login": "x"}]]}]]}`, http.StatusBadRequest},
+		{"PATCH", "/models/res.partner/1", `{"parent_id": {"name": "Holding"}}`, http.StatusBadRequest},
+		{"PATCH", "/models/res.partner/1", `{"parent_id": 3, "child_ids": [4, 5]}`, http.StatusNoContent},
+		{"DELETE", "/models/res.partner/1", "", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if w := do(gw, tc.method, tc.target, tc.body); w.Code != tc.status {
+			t.Errorf("%s %s: status %d, want %d (%s)", tc.method, tc.target, w.Code, tc.status, w.Body)
+		}
+	}
+
+	w := do(gw, "POST", "/models/res.partner", `{"name": "Acme", "is_company": true}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/models/res.partner/9" {
+		t.Errorf("create: status %d, location %q", w.Code, w.Header().Get("Location"))
+	}
+	if client.written["is_company"] != true {
+		t.Errorf("unexpected values %v", client.written)
+	}
+}
+
+func TestListConvertsFiltersByFieldType(t *testing.T) {
+	client := &fakeClient{}
+	gw := newGateway(client)
+	w := do(gw, "GET", "/models/res.partner?ref=123&parent_id=7&id=false", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	got := map[string]any{}
+	for _, item := range client.domain {
+		leaf := item.([]any)
+		got[leaf[0].(string)] = leaf[2]
+	}
+	want := map[string]any{"ref": "123", "parent_id": int64(7), "id": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filters = %#v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package odoorpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrAuthenticationFailed is returned when the server rejects the login.
+var ErrAuthenticationFailed = errors.New("authentication failed")
+
+// ErrTOTPRequired is returned by SessionAuthenticate when the user has
+// two-factor authentication enabled and no TOTP source was given.
+var ErrTOTPRequired = errors.New("two-factor authentication code required")
+
+var apiKeyRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// LooksLikeAPIKey reports whether secret has the shape of an Odoo API key
+// (40 lowercase hexadecimal characters). It is a heuristic used to give
+// better error messages; passwords of the same shape are possible.
+func LooksLikeAPIKey(secret string) bool {
+	return apiKeyRe.MatchString(secret)
+}
+
+// TOTPSource returns the current two-factor authentication code.
+type TOTPSource func(ctx context.Context) (string, error)
+
+// TOTPCode returns a TOTPSource for a code typed by the user.
+func TOTPCode(code string) TOTPSource {
+	return func(context.Context) (string, error) {
+		return code, nil
+	}
+}
+
+// TOTPFromSecret returns a TOTPSource generating codes from the base32
+// secret shown when the authenticator app was enrolled.
+func TOTPFromSecret(secret string) TOTPSource {
+	return func(context.Context) (string, error) {
+		return GenerateTOTP(secret, time.Now())
+	}
+}
+
+// GenerateTOTP computes the RFC 6238 code for secret at t, using the
+// parameters Odoo enrolls authenticator apps with: HMAC-SHA1, 30 second
+// steps and 6 digits.
+func GenerateTOTP(secret string, t time.Time) (string, error) {
+	clean := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(clean, "="))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", code%1_000_000), nil
+}
+
+type sessionInfo struct {
+	UID any `json:"uid"`
+}
+
+func (s sessionInfo) uid() int64 {
+	if uid, ok := s.UID.(float64); ok {
+		return int64(uid)
+	}
+	return 0
+}
+
+// SessionAuthenticate logs in through the web session routes, like the
+// browser does, and switches the client to cookie authenticated calls.
+//
+// It supports users with two-factor authentication: when the server asks
+// for a second factor the code returned by totp is submitted to
+// /web/login/totp. With a nil totp such users fail with ErrTOTPRequired.
+// After a session login the password is no longer sent with each call.
+func (c *RpcClient) SessionAuthenticate(ctx context.Context, username, password, db string, totp TOTPSource) (int64, error) {
+	params := map[string]any{"db": db, "login": username, "password": password}
+	var info sessionInfo
+	if err := c.rpc.CallPath(ctx, "/web/session/authenticate", "call", params, &info); err != nil {
+		return 0, err
+	}
+	uid := info.uid()
+	if uid == 0 {
+		if totp == nil {
+			return 0, ErrTOTPRequired
+		}
+		code, err := totp(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("totp: %w", err)
+		}
+		if err := c.submitTOTP(ctx, code); err != nil {
+			return 0, err
+		}
+		if err := c.rpc.CallPath(ctx, "/web/session/get_session_info", "call", map[string]any{}, &info); err != nil {
+			return 0, err
+		}
+		if uid = info.uid(); uid == 0 {
+			return 0, fmt.Errorf("%w: two-factor code rejected for %s", ErrAuthenticationFailed, username)
+		}
+	}
+	c.uid = uid
+	c.db = db
+	c.creds = nil
+	c.session = true
+	return uid, nil
+}
+
+var csrfRe = regexp.MustCompile(`name="csrf_token"\s+value="([^"]+)"`)
+
+// submitTOTP completes the second authentication step of a pending session.
+func (c *RpcClient) submitTOTP(ctx context.Context, code string) error {
+	httpClient := c.rpc.HTTPClient()
+	page := c.rpc.BaseURL() + "/web/login/totp"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, page, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("totp page request error: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read totp page: %w", err)
+	}
+	m := csrfRe.FindSubmatch(body)
+	if m == nil {
+		return fmt.Errorf("%w: no pending two-factor login", ErrAuthenticationFailed)
+	}
+
+	form := url.Values{
+		"csrf_token": {html.UnescapeString(string(m[1]))},
+		"totp_token": {code},
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, page, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("totp request error: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// callKwRequest returns the session route used by the web client to run
+// an ORM method, and its params.
+func callKwRequest(model, method string, args []any, kwargs map[string]any) (string, any, error) {
+	if kwargs == nil {
+		kwargs = map[string]any{}
+	}
+	params := map[string]any{
+		"model":  model,
+		"method": method,
+		"args":   args,
+		"kwargs": kwargs,
+	}
+	return "/web/dataset/call_kw/" + model + "/" + method, params, nil
+}
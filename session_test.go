@@ -0,0 +1,88 @@
+package odoorpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestGenerateTOTP(t *testing.T) {
+	// RFC 6238 SHA1 test vectors, truncated to six digits.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 2000000000: "279037"} {
+		got, err := odoorpc.GenerateTOTP(secret, time.Unix(unix, 0))
+		if err != nil || got != want {
+			t.Errorf("GenerateTOTP(%d) = %q, %v; want %q", unix, got, err, want)
+		}
+	}
+}
+
+func TestAuthenticateRejected(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) { return false, nil })
+	c := odoorpc.New(srv.URL, nil)
+	_, err := c.Authenticate(context.Background(), "admin", "wrong", "odoo")
+	if !errors.Is(err, odoorpc.ErrAuthenticationFailed) {
+		t.Fatalf("expected ErrAuthenticationFailed, got %v", err)
+	}
+}
+
+func TestSessionAuthenticateWithTOTP(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) { return nil, nil })
+	verified := false
+	writeResult := func(w http.ResponseWriter, r *http.Request, result any) {
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req["id"], "result": result})
+	}
+	srv.Mux.HandleFunc("/web/session/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "pending", Path: "/"})
+		writeResult(w, r, map[string]any{"uid": nil})
+	})
+	srv.Mux.HandleFunc("/web/login/totp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `<form><input type="hidden" name="csrf_token" value="tok&amp;1"/></form>`)
+			return
+		}
+		if r.FormValue("csrf_token") == "tok&1" && r.FormValue("totp_token") == "123456" {
+			verified = true
+		}
+		http.Redirect(w, r, "/web", http.StatusSeeOther)
+	})
+	srv.Mux.HandleFunc("/web", func(w http.ResponseWriter, r *http.Request) {})
+	srv.Mux.HandleFunc("/web/session/get_session_info", func(w http.ResponseWriter, r *http.Request) {
+		if verified {
+			writeResult(w, r, map[string]any{"uid": 2})
+			return
+		}
+		writeResult(w, r, map[string]any{"uid": nil})
+	})
+	srv.Mux.HandleFunc("/web/dataset/call_kw/res.partner/search", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session_id"); err != nil || c.Value != "pending" {
+			t.Errorf("missing session cookie")
+		}
+		writeResult(w, r, []int{1, 2})
+	})
+
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+	if _, err := c.SessionAuthenticate(ctx, "admin", "admin", "odoo", nil); !errors.Is(err, odoorpc.ErrTOTPRequired) {
+		t.Fatalf("expected ErrTOTPRequired, got %v", err)
+	}
+	uid, err := c.SessionAuthenticate(ctx, "admin", "admin", "odoo", odoorpc.TOTPCode("123456"))
+	if err != nil || uid != 2 {
+		t.Fatalf("SessionAuthenticate: uid=%d err=%v", uid, err)
+	}
+	ids, err := c.Search(ctx, "res.partner", nil, odoorpc.Options{})
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("Search over session: %v %v", ids, err)
+	}
+	if len(srv.Calls) != 0 {
+		t.Fatalf("expected no /jsonrpc calls, got %+v", srv.Calls)
+	}
+}
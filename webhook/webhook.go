@@ -0,0 +1,333 @@
+// Package webhook receives the webhooks sent by Odoo 17 automation rules
+// ("Send Webhook Notification" actions) and dispatches them per model.
+//
+//	rcv := webhook.NewReceiver(webhook.Options{
+//		Verify: webhook.TokenVerifier(os.Getenv("HOOK_TOKEN")),
+//		Client: client,
+//	})
+//	rcv.Handle("sale.order", func(ctx context.Context, ev webhook.Event) error {
+//		return sync(ev.ID, ev.Values["state"])
+//	}, "name", "state", "amount_total")
+//	http.Handle("/odoo/hook", rcv)
+//
+// Odoo posts a JSON object with the record id in "_id", the model in
+// "_model", the automation in "_action" and the fields selected on the
+// rule. It does not sign requests, so the usual way to authenticate them
+// is a secret token in the webhook URL
+// (https://example.com/odoo/hook?token=...), checked by TokenVerifier.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+// MaxBodySize bounds the size of accepted payloads.
+const MaxBodySize = 4 << 20
+
+// ErrUnverified is returned by verifiers rejecting a request.
+var ErrUnverified = errors.New("webhook: request verification failed")
+
+// Event is a record notification sent by an automation rule.
+type Event struct {
+	Model string
+	ID    int64
+	// Action names the automation rule, e.g. "Notify ERP(#12)".
+	Action string
+	// Values holds the fields of the record, as sent by Odoo and
+	// completed by the receiver when the handler asked for more fields.
+	// Relational values are ids, as Odoo reads them with load=None.
+	Values map[string]any
+}
+
+// HandlerFunc processes an event. An error answers the request with a 500
+// status and forgets the event, so that a retry is processed again.
+type HandlerFunc func(ctx context.Context, ev Event) error
+
+// Verifier authenticates a request given its body.
+type Verifier func(r *http.Request, body []byte) error
+
+// TokenVerifier accepts requests carrying token in the "token" query
+// parameter or in the X-Webhook-Token header.
+func TokenVerifier(token string) Verifier {
+	return func(r *http.Request, _ []byte) error {
+		got := r.URL.Query().Get("token")
+		if got == "" {
+			got = r.Header.Get("X-Webhook-Token")
+		}
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return ErrUnverified
+		}
+		return nil
+	}
+}
+
+// HMACVerifier accepts requests whose header holds the hex encoded
+// HMAC-SHA256 of the body with secret, optionally prefixed by "sha256=".
+// Odoo does not sign webhooks itself; this is for relays that do.
+func HMACVerifier(secret, header string) Verifier {
+	return func(r *http.Request, body []byte) error {
+		got, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(header), "sha256="))
+		if err != nil {
+			return ErrUnverified
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			return ErrUnverified
+		}
+		return nil
+	}
+}
+
+// Options configures a Receiver.
+type Options struct {
+	// Verify authenticates requests; nil accepts every request.
+	Verify Verifier
+	// Client reads the fields requested by handlers that are missing from
+	// the payload. Without it handlers only get the payload values.
+	Client odoorpc.Client
+	// DedupWindow enables dropping duplicate deliveries of an event within
+	// the window. Odoo payloads carry no delivery id, so events are told
+	// apart by model, id and "write_date", which the rule must send; events
+	// without it are always processed. Zero disables deduplication.
+	DedupWindow time.Duration
+	// OnError is called with the errors answered to Odoo.
+	OnError func(r *http.Request, err error)
+}
+
+type route struct {
+	handle HandlerFunc
+	fields []string
+}
+
+// Receiver is an http.Handler dispatching webhook events to the handlers
+// registered per model.
+type Receiver struct {
+	opts Options
+
+	mu     sync.Mutex
+	routes map[string]route
+	seen   map[string]delivery
+}
+
+// delivery is the state of a deduplicated event.
+type delivery struct {
+	at   time.Time
+	done bool
+}
+
+// NewReceiver returns a Receiver without handlers.
+func NewReceiver(opts Options) *Receiver {
+	return &Receiver{opts: opts, routes: map[string]route{}, seen: map[string]delivery{}}
+}
+
+// Handle registers h for the events of model. fields lists the fields h
+// needs; those missing from the payload are read from Odoo.
+func (rc *Receiver) Handle(model string, h HandlerFunc, fields ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.routes[model] = route{handle: h, fields: fields}
+}
+
+// ParseEvent decodes an Odoo webhook payload.
+func ParseEvent(body []byte) (Event, error) {
+	var values map[string]any
+	if err := json.Unmarshal(body, &values); err != nil {
+		return Event{}, fmt.Errorf("webhook: invalid payload: %w", err)
+	}
+	ev := Event{Values: values}
+	ev.Model, _ = values["_model"].(string)
+	ev.Action, _ = values["_action"].(string)
+	id, _ := values["_id"].(float64)
+	ev.ID = int64(id)
+	if ev.Model == "" || ev.ID == 0 {
+		return Event{}, errors.New("webhook: payload has no _model or _id")
+	}
+	delete(values, "_model")
+	delete(values, "_action")
+	delete(values, "_id")
+	return ev, nil
+}
+
+func (rc *Receiver) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if rc.opts.OnError != nil {
+		rc.opts.OnError(r, err)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if err != nil {
+		rc.fail(w, r, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	if rc.opts.Verify != nil {
+		if err := rc.opts.Verify(r, body); err != nil {
+			rc.fail(w, r, http.StatusUnauthorized, err)
+			return
+		}
+	}
+	ev, err := ParseEvent(body)
+	if err != nil {
+		rc.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	rc.mu.Lock()
+	rt, ok := rc.routes[ev.Model]
+	rc.mu.Unlock()
+	if !ok {
+		rc.fail(w, r, http.StatusNotFound, fmt.Errorf("webhook: no handler for %s", ev.Model))
+		return
+	}
+
+	key := rc.dedupKey(ev)
+	switch rc.claim(key) {
+	case claimDone:
+		w.WriteHeader(http.StatusOK)
+		return
+	case claimInFlight:
+		// The first delivery may still fail: do not acknowledge this one.
+		rc.fail(w, r, http.StatusConflict, fmt.Errorf("webhook: %s(%d) is being processed", ev.Model, ev.ID))
+		return
+	}
+	handled := false
+	defer func() {
+		// Forget failed or panicking deliveries so that a retry is
+		// processed again.
+		if !handled {
+			rc.forget(key)
+		}
+	}()
+	if err := rc.complete(r.Context(), &ev, rt.fields); err != nil {
+		rc.fail(w, r, http.StatusBadGateway, err)
+		return
+	}
+	if err := rt.handle(r.Context(), ev); err != nil {
+		rc.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	handled = true
+	rc.done(key)
+	w.WriteHeader(http.StatusOK)
+}
+
+// complete reads the requested fields missing from the payload.
+func (rc *Receiver) complete(ctx context.Context, ev *Event, fields []string) error {
+	var missing []string
+	for _, f := range fields {
+		if _, ok := ev.Values[f]; !ok {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) == 0 || rc.opts.Client == nil {
+		return nil
+	}
+	recs, err := rc.opts.Client.Read(ctx, ev.Model, []int64{ev.ID}, odoorpc.Options{Fields: missing})
+	if err != nil {
+		return err
+	}
+	if len(recs) == 0 {
+		return fmt.Errorf("webhook: %s(%d) no longer exists", ev.Model, ev.ID)
+	}
+	for _, f := range missing {
+		ev.Values[f] = relationID(recs[0][f])
+	}
+	return nil
+}
+
+// relationID reduces a many2one value read as [id, display_name] to the
+// id, like a read with load=None.
+func relationID(v any) any {
+	if pair, ok := v.([]any); ok && len(pair) == 2 {
+		if _, isName := pair[1].(string); isName {
+			return pair[0]
+		}
+	}
+	return v
+}
+
+// dedupKey identifies an event by model, id and write_date, or returns ""
+// when deduplication is disabled or not possible.
+func (rc *Receiver) dedupKey(ev Event) string {
+	if rc.opts.DedupWindow <= 0 {
+		return ""
+	}
+	writeDate, _ := ev.Values["write_date"].(string)
+	if writeDate == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s,%d,%s", ev.Model, ev.ID, writeDate)
+}
+
+// Results of claim.
+const (
+	claimNew = iota
+	claimInFlight
+	claimDone
+)
+
+// claim reports whether key is new, being processed or was processed
+// within the dedup window, and marks new keys as being processed.
+func (rc *Receiver) claim(key string) int {
+	if key == "" {
+		return claimNew
+	}
+	now := time.Now()
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	// In-flight entries older than the window belong to deliveries that
+	// never finished and would otherwise block their retries forever.
+	for k, d := range rc.seen {
+		if now.Sub(d.at) > rc.opts.DedupWindow {
+			delete(rc.seen, k)
+		}
+	}
+	if d, ok := rc.seen[key]; ok {
+		if d.done {
+			return claimDone
+		}
+		return claimInFlight
+	}
+	rc.seen[key] = delivery{at: now}
+	return claimNew
+}
+
+// done marks key as processed.
+func (rc *Receiver) done(key string) {
+	if key == "" {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.seen[key] = delivery{at: time.Now(), done: true}
+}
+
+func (rc *Receiver) forget(key string) {
+	if key == "" {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.seen, key)
+}
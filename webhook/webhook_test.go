@@ -0,0 +1,156 @@
+package webhook_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Guadalsistema/odoorpc"
+	"github.com/Guadalsistema/odoorpc/webhook"
+)
+
+type readClient struct {
+	odoorpc.Client
+	reads [][]string
+}
+
+func (c *readClient) Read(ctx context.Context, model string, ids []int64, opts odoorpc.Options) ([]map[string]any, error) {
+	c.reads = append(c.reads, opts.Fields)
+	return []map[string]any{{"id": float64(ids[0]), "amount_total": 120.5, "partner_id": []any{float64(3), "Acme"}}}, nil
+}
+
+const payload = `{"_id": 7, "_model": "sale.order", "_action": "Notify ERP(#3)", "name": "S00007", "state": "sale", "write_date": "2024-05-02 10:00:00"}`
+
+func post(rc http.Handler, target, body string) int {
+	w := httptest.NewRecorder()
+	rc.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+	return w.Code
+}
+
+func TestReceiverDispatchesAndCompletesRecords(t *testing.T) {
+	client := &readClient{}
+	rc := webhook.NewReceiver(webhook.Options{Verify: webhook.TokenVerifier("s3cret"), Client: client, DedupWindow: time.Minute})
+	var events []webhook.Event
+	fail := false
+	rc.Handle("sale.order", func(ctx context.Context, ev webhook.Event) error {
+		if fail {
+			return errors.New("boom")
+		}
+		events = append(events, ev)
+		return nil
+	}, "name", "amount_total", "partner_id")
+
+	if code := post(rc, "/hook?token=wrong", payload); code != http.StatusUnauthorized {
+		t.Errorf("bad token: status %d", code)
+	}
+	if code := post(rc, "/hook?token=s3cret", `{"_id": 1, "_model": "res.partner"}`); code != http.StatusNotFound {
+		t.Errorf("unknown model: status %d", code)
+	}
+
+	fail = true
+	if code := post(rc, "/hook?token=s3cret", payload); code != http.StatusInternalServerError {
+		t.Errorf("failing handler: status %d", code)
+	}
+	fail = false
+	if code := post(rc, "/hook?token=s3cret", payload); code != http.StatusOK {
+		t.Fatalf("retry after failure: status %d", code)
+	}
+	if code := post(rc, "/hook?token=s3cret", payload); code != http.StatusOK {
+		t.Errorf("duplicate: status %d", code)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+	ev := events[0]
+	if ev.Model != "sale.order" || ev.ID != 7 || ev.Action != "Notify ERP(#3)" {
+		t.Errorf("unexpected event %+v", ev)
+	}
+	if ev.Values["amount_total"] != 120.5 || ev.Values["state"] != "sale" || ev.Values["partner_id"] != float64(3) {
+		t.Errorf("unexpected values %v", ev.Values)
+	}
+	if _, ok := ev.Values["_id"]; ok {
+		t.Errorf("metadata should not be in values")
+	}
+	if len(client.reads) != 2 || len(client.reads[1]) != 2 || client.reads[1][0] != "amount_total" {
+		t.Errorf("only missing fields should be read, got %v", client.reads)
+	}
+}
+
+func TestReceiverDedup(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var handled int
+	rc := webhook.NewReceiver(webhook.Options{DedupWindow: time.Minute})
+	rc.Handle("sale.order", func(ctx context.Context, ev webhook.Event) error {
+		handled++
+		if ev.ID == 8 {
+			started <- struct{}{}
+			<-release
+		}
+		return nil
+	})
+
+	// Without write_date identical payloads may be distinct changes.
+	toggle := `{"_id": 7, "_model": "sale.order", "state": "draft"}`
+	post(rc, "/hook", toggle)
+	post(rc, "/hook", toggle)
+	if handled != 2 {
+		t.Errorf("payloads without write_date were deduplicated: %d handled", handled)
+	}
+
+	inFlight := `{"_id": 8, "_model": "sale.order", "write_date": "2024-05-02 10:00:00"}`
+	first := make(chan int)
+	go func() { first <- post(rc, "/hook", inFlight) }()
+	<-started
+	if code := post(rc, "/hook", inFlight); code != http.StatusConflict {
+		t.Errorf("duplicate of an in-flight event: status %d", code)
+	}
+	close(release)
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("first delivery: status %d", code)
+	}
+	if code := post(rc, "/hook", inFlight); code != http.StatusOK || handled != 3 {
+		t.Errorf("processed duplicate: status %d, %d handled", code, handled)
+	}
+}
+
+func TestHMACVerifier(t *testing.T) {
+	verify := webhook.HMACVerifier("key", "X-Signature")
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte(payload))
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	if err := verify(r, []byte(payload)); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if err := verify(r, []byte(payload+" ")); !errors.Is(err, webhook.ErrUnverified) {
+		t.Errorf("tampered body accepted: %v", err)
+	}
+}
+
+func TestReceiverForgetsPanickingDeliveries(t *testing.T) {
+	rc := webhook.NewReceiver(webhook.Options{DedupWindow: time.Minute})
+	panicking := true
+	rc.Handle("sale.order", func(ctx context.Context, ev webhook.Event) error {
+		if panicking {
+			panic("boom")
+		}
+		return nil
+	})
+	func() {
+		defer func() { recover() }()
+		post(rc, "/hook", payload)
+	}()
+	panicking = false
+	if code := post(rc, "/hook", payload); code != http.StatusOK {
+		t.Errorf("retry after a panic: status %d", code)
+	}
+}
@@ -0,0 +1,190 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// serverMeta caches data about the server that does not change during the
+// life of a client.
+type serverMeta struct {
+	mu      sync.Mutex
+	version *ServerVersion
+	fields  map[string]map[string]fieldMeta
+	// webSession is set once a web session was opened for an RPC
+	// authenticated client.
+	webSession bool
+}
+
+type fieldMeta struct {
+	Type     string `json:"type"`
+	Relation string `json:"relation"`
+}
+
+func (f fieldMeta) relational() bool {
+	switch f.Type {
+	case "many2one", "one2many", "many2many":
+		return true
+	}
+	return false
+}
+
+// serverVersion returns the server version, asking the server once.
+func (c *RpcClient) serverVersion(ctx context.Context) (ServerVersion, error) {
+	c.meta.mu.Lock()
+	cached := c.meta.version
+	c.meta.mu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	v, err := c.Version(ctx)
+	if err != nil {
+		return ServerVersion{}, err
+	}
+	c.meta.mu.Lock()
+	c.meta.version = &v
+	c.meta.mu.Unlock()
+	return v, nil
+}
+
+// fieldsMeta returns the type and comodel of the fields of model, asking
+// the server once per model.
+func (c *RpcClient) fieldsMeta(ctx context.Context, model string) (map[string]fieldMeta, error) {
+	c.meta.mu.Lock()
+	cached, ok := c.meta.fields[model]
+	c.meta.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	var fields map[string]fieldMeta
+	kwargs := map[string]any{"attributes": []string{"type", "relation"}}
+	if err := c.executeKw(ctx, model, "fields_get", nil, kwargs, &fields); err != nil {
+		return nil, err
+	}
+	c.meta.mu.Lock()
+	if c.meta.fields == nil {
+		c.meta.fields = map[string]map[string]fieldMeta{}
+	}
+	c.meta.fields[model] = fields
+	c.meta.mu.Unlock()
+	return fields, nil
+}
+
+// Spec selects the fields returned by WebRead and WebSearchRead, keyed by
+// field name. It mirrors the `specification` argument of Odoo's web_read.
+type Spec map[string]FieldSpec
+
+// FieldSpec describes how a field is read. For relational fields, Fields
+// selects the fields of the related records, which are then returned
+// nested: a map for many2one fields and a slice of maps for x2many fields.
+// Without Fields, many2one values are returned as the related id and
+// x2many values as the list of related ids.
+type FieldSpec struct {
+	Fields Spec
+	// Limit and Order apply to the related records of x2many fields.
+	Limit int
+	Order string
+	// Context is used to read the related records.
+	Context map[string]any
+}
+
+// MarshalJSON encodes the spec in the web_read format.
+func (f FieldSpec) MarshalJSON() ([]byte, error) {
+	m := map[string]any{}
+	if len(f.Fields) > 0 {
+		m["fields"] = f.Fields
+	}
+	if f.Limit > 0 {
+		m["limit"] = f.Limit
+	}
+	if f.Order != "" {
+		m["order"] = f.Order
+	}
+	if len(f.Context) > 0 {
+		m["context"] = f.Context
+	}
+	return json.Marshal(m)
+}
+
+// webReadMinMajor is the first Odoo version providing web_read.
+const webReadMinMajor = 17
+
+func (c *RpcClient) hasWebRead(ctx context.Context) (bool, error) {
+	v, err := c.serverVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+	return v.ServerVersionInfo.Major >= webReadMinMajor, nil
+}
+
+// WebRead reads records with their related records nested according to
+// spec in a single round trip on Odoo 17 and later. On older servers it
+// falls back to one batched read per relation level, stitching the results
+// into the same shape.
+func (c *RpcClient) WebRead(ctx context.Context, model string, ids []int64, spec Spec) ([]map[string]any, error) {
+	if len(ids) == 0 {
+		return []map[string]any{}, nil
+	}
+	native, err := c.hasWebRead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !native {
+		return c.readNested(ctx, model, ids, spec, Options{})
+	}
+	idArgs := make([]any, len(ids))
+	for i, id := range ids {
+		idArgs[i] = id
+	}
+	var res []map[string]any
+	if err := c.executeKw(ctx, model, "web_read", []any{idArgs}, map[string]any{"specification": spec}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WebSearchRead searches model and reads the matching records like
+// WebRead. opts provides Limit, Offset, Order and Context; its Fields are
+// ignored in favor of spec.
+func (c *RpcClient) WebSearchRead(ctx context.Context, model string, domain Domain, spec Spec, opts Options) ([]map[string]any, error) {
+	if domain == nil {
+		domain = Domain{}
+	}
+	native, err := c.hasWebRead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !native {
+		ids, err := c.Search(ctx, model, domain, opts)
+		if err != nil {
+			return nil, err
+		}
+		return c.readNested(ctx, model, ids, spec, opts)
+	}
+	kwargs := Options{Limit: opts.Limit, Offset: opts.Offset, Order: opts.Order, Context: opts.Context}.Kwargs()
+	kwargs["domain"] = domain
+	kwargs["specification"] = spec
+	var res struct {
+		Records []map[string]any `json:"records"`
+	}
+	if err := c.executeKw(ctx, model, "web_search_read", nil, kwargs, &res); err != nil {
+		return nil, err
+	}
+	return res.Records, nil
+}
+
+// readNested emulates web_read with plain read calls: one per model and
+// nesting level, whatever the number of records. Every read runs with
+// opts.Context, as web_search_read does.
+func (c *RpcClient) readNested(ctx context.Context, model string, ids []int64, spec Spec, opts Options) ([]map[string]any, error) {
+	c = c.withCallContext(opts.Context)
+	recs, err := c.readChunks(ctx, model, ids, specFields(spec), DefaultPrefetchChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.prefetch(ctx, model, recs, spec, DefaultPrefetchChunkSize); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
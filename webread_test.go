@@ -0,0 +1,202 @@
+package odoorpc_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+var orderSpec = odoorpc.Spec{
+	"name": {},
+	"order_line": {Fields: odoorpc.Spec{
+		"product_id": {Fields: odoorpc.Spec{"default_code": {}}},
+	}},
+	"partner_id": {},
+}
+
+func TestWebReadUsesWebReadOnRecentServers(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "version":
+			return map[string]any{"server_version": "17.0", "server_version_info": []any{17, 0, 0, "final", 0, ""}}, nil
+		case "web_read":
+			return []any{map[string]any{"id": 7, "name": "S00007"}}, nil
+		}
+		t.Errorf("unexpected call %s", call.Method)
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	recs, err := c.WebRead(context.Background(), "sale.order", []int64{7}, orderSpec)
+	if err != nil {
+		t.Fatalf("WebRead: %v", err)
+	}
+	if len(recs) != 1 || recs[0]["name"] != "S00007" {
+		t.Fatalf("unexpected records %v", recs)
+	}
+	spec := srv.Calls[1].Kwargs["specification"]
+	want := map[string]any{
+		"name":       map[string]any{},
+		"partner_id": map[string]any{},
+		"order_line": map[string]any{"fields": map[string]any{
+			"product_id": map[string]any{"fields": map[string]any{"default_code": map[string]any{}}},
+		}},
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Errorf("unexpected specification %v", spec)
+	}
+}
+
+func TestWebReadStitchesReadsOnOlderServers(t *testing.T) {
+	fields := map[string]any{
+		"sale.order": map[string]any{
+			"name":       map[string]any{"type": "char"},
+			"partner_id": map[string]any{"type": "many2one", "relation": "res.partner"},
+			"order_line": map[string]any{"type": "one2many", "relation": "sale.order.line"},
+		},
+		"sale.order.line": map[string]any{
+			"product_id": map[string]any{"type": "many2one", "relation": "product.product"},
+		},
+		"product.product": map[string]any{
+			"default_code": map[string]any{"type": "char"},
+		},
+	}
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "version":
+			return map[string]any{"server_version": "16.0", "server_version_info": []any{16, 0, 0, "final", 0, ""}}, nil
+		case "fields_get":
+			return fields[call.Model], nil
+		case "read":
+			switch call.Model {
+			case "sale.order":
+				return []any{
+					map[string]any{"id": 1, "name": "S1", "partner_id": []any{3, "Acme"}, "order_line": []any{10, 11}},
+					map[string]any{"id": 2, "name": "S2", "partner_id": false, "order_line": []any{12}},
+				}, nil
+			case "sale.order.line":
+				return []any{
+					map[string]any{"id": 10, "product_id": []any{5, "Desk"}},
+					map[string]any{"id": 11, "product_id": []any{6, "Chair"}},
+					map[string]any{"id": 12, "product_id": []any{5, "Desk"}},
+				}, nil
+			case "product.product":
+				return []any{
+					map[string]any{"id": 5, "default_code": "DESK"},
+					map[string]any{"id": 6, "default_code": "CHAIR"},
+				}, nil
+			}
+		}
+		t.Errorf("unexpected call %s.%s", call.Model, call.Method)
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	recs, err := c.WebRead(context.Background(), "sale.order", []int64{1, 2}, orderSpec)
+	if err != nil {
+		t.Fatalf("WebRead: %v", err)
+	}
+	if got := recs[0]["partner_id"]; got != int64(3) {
+		t.Errorf("many2one without sub fields should be an id, got %#v", got)
+	}
+	if got := recs[1]["partner_id"]; got != false {
+		t.Errorf("empty many2one should be false, got %#v", got)
+	}
+	lines := recs[0]["order_line"].([]map[string]any)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %v", lines)
+	}
+	product := lines[1]["product_id"].(map[string]any)
+	if product["default_code"] != "CHAIR" {
+		t.Errorf("unexpected nested product %v", product)
+	}
+	var reads int
+	for _, call := range srv.Calls {
+		if call.Method == "read" {
+			reads++
+			if call.Model == "product.product" && len(call.Args[0].([]any)) != 2 {
+				t.Errorf("related ids should be deduplicated: %v", call.Args[0])
+			}
+		}
+	}
+	if reads != 3 {
+		t.Errorf("expected one read per level, got %d", reads)
+	}
+}
+
+func TestWebReadFallbackHonoursOrderAndLimit(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Method == "version":
+			return map[string]any{"server_version": "16.0", "server_version_info": []any{16, 0, 0, "final", 0, ""}}, nil
+		case call.Method == "fields_get" && call.Model == "sale.order":
+			return map[string]any{"order_line": map[string]any{"type": "one2many", "relation": "sale.order.line"}}, nil
+		case call.Method == "fields_get":
+			return map[string]any{"sequence": map[string]any{"type": "integer"}}, nil
+		case call.Method == "read" && call.Model == "sale.order":
+			return []any{map[string]any{"id": 1, "order_line": []any{10, 11, 12}}}, nil
+		case call.Method == "read":
+			return []any{
+				map[string]any{"id": 10, "sequence": 1},
+				map[string]any{"id": 11, "sequence": 3},
+				map[string]any{"id": 12, "sequence": 2},
+			}, nil
+		case call.Method == "search":
+			if call.Kwargs["order"] != "sequence desc" {
+				t.Errorf("unexpected order %v", call.Kwargs["order"])
+			}
+			return []any{11, 12, 10}, nil
+		}
+		t.Errorf("unexpected call %s.%s", call.Model, call.Method)
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	spec := odoorpc.Spec{"order_line": {Fields: odoorpc.Spec{"sequence": {}}, Order: "sequence desc", Limit: 2}}
+	recs, err := c.WebRead(context.Background(), "sale.order", []int64{1}, spec)
+	if err != nil {
+		t.Fatalf("WebRead: %v", err)
+	}
+	var ids []any
+	for _, line := range recs[0]["order_line"].([]map[string]any) {
+		ids = append(ids, line["id"])
+	}
+	if !reflect.DeepEqual(ids, []any{float64(11), float64(12)}) {
+		t.Errorf("unexpected ordered lines %v", ids)
+	}
+}
+
+func TestWebSearchReadFallbackPassesContext(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Method == "version":
+			return map[string]any{"server_version": "16.0", "server_version_info": []any{16, 0, 0, "final", 0, ""}}, nil
+		case call.Method == "fields_get" && call.Model == "sale.order":
+			return map[string]any{"partner_id": map[string]any{"type": "many2one", "relation": "res.partner"}}, nil
+		case call.Method == "fields_get":
+			return map[string]any{"name": map[string]any{"type": "char"}}, nil
+		case call.Method == "search":
+			return []any{1}, nil
+		case call.Method == "read" && call.Model == "sale.order":
+			return []any{map[string]any{"id": 1, "partner_id": []any{3, "Acme"}}}, nil
+		case call.Method == "read":
+			return []any{map[string]any{"id": 3, "name": "Acme"}}, nil
+		}
+		t.Errorf("unexpected call %s.%s", call.Model, call.Method)
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	spec := odoorpc.Spec{"partner_id": {Fields: odoorpc.Spec{"name": {}}}}
+	opts := odoorpc.Options{Context: map[string]any{"lang": "es_ES"}}
+	if _, err := c.WebSearchRead(context.Background(), "sale.order", nil, spec, opts); err != nil {
+		t.Fatalf("WebSearchRead: %v", err)
+	}
+	for _, call := range srv.Calls {
+		if call.Method != "search" && call.Method != "read" {
+			continue
+		}
+		callCtx, _ := call.Kwargs["context"].(map[string]any)
+		if callCtx["lang"] != "es_ES" {
+			t.Errorf("%s.%s ran without the options context: %v", call.Model, call.Method, call.Kwargs)
+		}
+	}
+}
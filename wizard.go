@@ -0,0 +1,140 @@
+package odoorpc
+
+import (
+	"context"
+	"maps"
+)
+
+// WizardOptions controls RunWizard.
+type WizardOptions struct {
+	// ActiveModel and ActiveIDs are the records the wizard applies to, set
+	// as active_model, active_ids and active_id in its context.
+	ActiveModel string
+	ActiveIDs   []int64
+	// Values are set on the wizard over its defaults.
+	Values map[string]any
+	// Onchange runs the onchange methods triggered by Values before the
+	// wizard is created, as the form view would.
+	Onchange bool
+	// Context is merged into the wizard context.
+	Context map[string]any
+}
+
+func (o WizardOptions) context() map[string]any {
+	wctx := map[string]any{}
+	if o.ActiveModel != "" {
+		wctx["active_model"] = o.ActiveModel
+		wctx["active_ids"] = o.ActiveIDs
+		if len(o.ActiveIDs) > 0 {
+			wctx["active_id"] = o.ActiveIDs[0]
+		}
+	}
+	maps.Copy(wctx, o.Context)
+	return wctx
+}
+
+// RunWizard runs a wizard the way the web client does: it prefills the
+// transient model with default_get, applies opts.Values (and their
+// onchanges), creates the record and calls its button method. It returns
+// the action the button answered, or nil when it returned none.
+//
+//	action, err := c.RunWizard(ctx, "stock.backorder.confirmation", "process", odoorpc.WizardOptions{
+//		ActiveModel: "stock.picking",
+//		ActiveIDs:   []int64{pickingID},
+//		Context:     map[string]any{"button_validate_picking_ids": []int64{pickingID}},
+//	})
+func (c *RpcClient) RunWizard(ctx context.Context, model, button string, opts WizardOptions) (*Action, error) {
+	kwargs := Options{Context: opts.context()}.Kwargs()
+	values, err := c.wizardValues(ctx, model, opts, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	var id int64
+	if err := c.executeKw(ctx, model, "create", []any{values}, kwargs, &id); err != nil {
+		return nil, err
+	}
+	var res any
+	if err := c.executeKw(ctx, model, button, []any{[]int64{id}}, kwargs, &res); err != nil {
+		return nil, err
+	}
+	return DecodeAction(res)
+}
+
+// wizardValues returns the creation values of a wizard: defaults, then the
+// onchange results, then the given values.
+func (c *RpcClient) wizardValues(ctx context.Context, model string, opts WizardOptions, kwargs map[string]any) (map[string]any, error) {
+	meta, err := c.fieldsMeta(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, 0, len(meta))
+	for name := range meta {
+		fields = append(fields, name)
+	}
+	values := map[string]any{}
+	if err := c.executeKw(ctx, model, "default_get", []any{fields}, kwargs, &values); err != nil {
+		return nil, err
+	}
+	maps.Copy(values, opts.Values)
+	if opts.Onchange && len(opts.Values) > 0 {
+		changed, err := c.onchange(ctx, model, values, opts.Values, fields, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		for name, v := range changed {
+			if _, set := opts.Values[name]; !set {
+				values[name] = onchangeValue(v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// onchange calls the onchange method of model for the fields in changed
+// and returns the updated values. Its signature changed in Odoo 17.
+func (c *RpcClient) onchange(ctx context.Context, model string, values, changed map[string]any, fields []string, kwargs map[string]any) (map[string]any, error) {
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	v, err := c.serverVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec := map[string]any{}
+	for _, f := range fields {
+		if v.ServerVersionInfo.Major >= 17 {
+			spec[f] = map[string]any{}
+		} else {
+			spec[f] = "1"
+		}
+	}
+	var res struct {
+		Value map[string]any `json:"value"`
+	}
+	if err := c.executeKw(ctx, model, "onchange", []any{[]int64{}, values, names, spec}, kwargs, &res); err != nil {
+		return nil, err
+	}
+	return res.Value, nil
+}
+
+// onchangeValue converts a relational value returned by onchange to the
+// form expected by create: [id, name] pairs (Odoo 16 and older) and
+// {"id": ..., "display_name": ...} objects (Odoo 17) become ids.
+func onchangeValue(v any) any {
+	switch v := v.(type) {
+	case []any:
+		if len(v) == 2 {
+			if id, ok := v[0].(float64); ok {
+				if _, ok := v[1].(string); ok {
+					return int64(id)
+				}
+			}
+		}
+	case map[string]any:
+		if id, ok := v["id"].(float64); ok {
+			return int64(id)
+		}
+	}
+	return v
+}
@@ -0,0 +1,70 @@
+package odoorpc_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestRunWizard(t *testing.T) {
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch call.Method {
+		case "version":
+			return map[string]any{"server_version": "17.0", "server_version_info": []any{17, 0, 0, "final", 0, ""}}, nil
+		case "fields_get":
+			return map[string]any{
+				"journal_id": map[string]any{"type": "many2one", "relation": "account.journal"},
+				"partner_id": map[string]any{"type": "many2one", "relation": "res.partner"},
+				"amount":     map[string]any{"type": "monetary"},
+			}, nil
+		case "default_get":
+			return map[string]any{"journal_id": 3, "amount": 100}, nil
+		case "onchange":
+			return map[string]any{"value": map[string]any{
+				"partner_id": map[string]any{"id": 5, "display_name": "Azure Interior"},
+				"journal_id": 9,
+			}}, nil
+		case "create":
+			return 40, nil
+		case "action_create_payments":
+			return map[string]any{"type": "ir.actions.act_window", "name": "Payments", "res_model": "account.payment", "res_id": 77}, nil
+		}
+		return nil, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	action, err := c.RunWizard(context.Background(), "account.payment.register", "action_create_payments", odoorpc.WizardOptions{
+		ActiveModel: "account.move",
+		ActiveIDs:   []int64{21, 22},
+		Values:      map[string]any{"journal_id": 4},
+		Onchange:    true,
+	})
+	if err != nil {
+		t.Fatalf("RunWizard: %v", err)
+	}
+	if action == nil || action.Type != "ir.actions.act_window" || action.Raw["res_model"] != "account.payment" {
+		t.Fatalf("unexpected action %+v", action)
+	}
+
+	calls := map[string]fakeCall{}
+	for _, call := range srv.Calls {
+		calls[call.Method] = call
+	}
+	wantCtx := map[string]any{"active_model": "account.move", "active_ids": []any{float64(21), float64(22)}, "active_id": float64(21)}
+	for _, method := range []string{"default_get", "onchange", "create", "action_create_payments"} {
+		if got := calls[method].Kwargs["context"]; !reflect.DeepEqual(got, wantCtx) {
+			t.Errorf("%s context = %v", method, got)
+		}
+	}
+	if names := calls["onchange"].Args[2]; !reflect.DeepEqual(names, []any{"journal_id"}) {
+		t.Errorf("onchange fields = %v", names)
+	}
+	wantValues := map[string]any{"journal_id": float64(4), "amount": float64(100), "partner_id": float64(5)}
+	if got := calls["create"].Args[0]; !reflect.DeepEqual(got, wantValues) {
+		t.Errorf("create values = %v", got)
+	}
+	if got := calls["action_create_payments"].Args; !reflect.DeepEqual(got, []any{[]any{float64(40)}}) {
+		t.Errorf("button args = %v", got)
+	}
+}
@@ -0,0 +1,192 @@
+package odoorpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrXMLIDNotFound is returned when an external id does not exist.
+var ErrXMLIDNotFound = errors.New("external id not found")
+
+// XMLIDRef is an external id (`module.name`) resolved through `ir.model.data`.
+type XMLIDRef struct {
+	Module string
+	Name   string
+	Model  string
+	ID     int64
+}
+
+// XMLID returns the fully qualified external id.
+func (r XMLIDRef) XMLID() string {
+	return r.Module + "." + r.Name
+}
+
+// SplitXMLID splits a fully qualified external id into module and name.
+func SplitXMLID(xmlid string) (module, name string, err error) {
+	module, name, ok := strings.Cut(xmlid, ".")
+	if !ok || module == "" || name == "" {
+		return "", "", fmt.Errorf("invalid external id %q: expected module.name", xmlid)
+	}
+	return module, name, nil
+}
+
+// ResolveXMLIDs resolves external ids in a single round trip. Ids that do
+// not exist are absent from the returned map.
+func (c *RpcClient) ResolveXMLIDs(ctx context.Context, xmlids []string) (map[string]XMLIDRef, error) {
+	byModule := map[string][]string{}
+	for _, xmlid := range xmlids {
+		module, name, err := SplitXMLID(xmlid)
+		if err != nil {
+			return nil, err
+		}
+		byModule[module] = append(byModule[module], name)
+	}
+	if len(byModule) == 0 {
+		return map[string]XMLIDRef{}, nil
+	}
+
+	modules := make([]string, 0, len(byModule))
+	for module := range byModule {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	// Flat prefix form: n-1 "|" followed by one "&" pair per module.
+	domain := make(Domain, 0, 4*len(modules))
+	for range modules[1:] {
+		domain = append(domain, "|")
+	}
+	for _, module := range modules {
+		domain = append(domain, "&", []any{"module", "=", module}, []any{"name", "in", byModule[module]})
+	}
+
+	recs, err := c.SearchRead(ctx, "ir.model.data", domain, Options{Fields: []string{"module", "name", "model", "res_id"}})
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string]XMLIDRef, len(recs))
+	for _, rec := range recs {
+		ref := xmlidFromRecord(rec)
+		refs[ref.XMLID()] = ref
+	}
+	return refs, nil
+}
+
+// ResolveXMLID resolves a single external id. It returns an error wrapping
+// ErrXMLIDNotFound when the id does not exist.
+func (c *RpcClient) ResolveXMLID(ctx context.Context, xmlid string) (XMLIDRef, error) {
+	refs, err := c.ResolveXMLIDs(ctx, []string{xmlid})
+	if err != nil {
+		return XMLIDRef{}, err
+	}
+	ref, ok := refs[xmlid]
+	if !ok {
+		return XMLIDRef{}, fmt.Errorf("%w: %s", ErrXMLIDNotFound, xmlid)
+	}
+	return ref, nil
+}
+
+// XMLIDs maps record ids of a model to their external ids. A record may have
+// several external ids; records without any are absent from the map.
+func (c *RpcClient) XMLIDs(ctx context.Context, model string, ids []int64) (map[int64][]string, error) {
+	if len(ids) == 0 {
+		return map[int64][]string{}, nil
+	}
+	domain := NewDomain().Equals("model", model).In("res_id", ids)
+	recs, err := c.SearchRead(ctx, "ir.model.data", domain, Options{Fields: []string{"module", "name", "model", "res_id"}, Order: "id"})
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64][]string, len(recs))
+	for _, rec := range recs {
+		ref := xmlidFromRecord(rec)
+		res[ref.ID] = append(res[ref.ID], ref.XMLID())
+	}
+	return res, nil
+}
+
+// CreateWithXMLID creates a record and registers xmlid for it.
+//
+// The two writes cannot share a transaction over RPC; if registering the
+// external id fails the record is unlinked again before returning the error.
+func (c *RpcClient) CreateWithXMLID(ctx context.Context, model, xmlid string, values map[string]any) (int64, error) {
+	module, name, err := SplitXMLID(xmlid)
+	if err != nil {
+		return 0, err
+	}
+	id, err := c.Create(ctx, model, values)
+	if err != nil {
+		return 0, err
+	}
+	_, err = c.Create(ctx, "ir.model.data", map[string]any{
+		"module":   module,
+		"name":     name,
+		"model":    model,
+		"res_id":   id,
+		"noupdate": true,
+	})
+	if err != nil {
+		if _, unlinkErr := c.Unlink(ctx, model, []int64{id}); unlinkErr != nil {
+			return 0, fmt.Errorf("register external id %s: %w (and failed to remove record %d: %v)", xmlid, err, id, unlinkErr)
+		}
+		return 0, fmt.Errorf("register external id %s: %w", xmlid, err)
+	}
+	return id, nil
+}
+
+// UpsertByXMLID updates the record identified by xmlid with values, or
+// creates it and registers the external id when it does not exist yet. An
+// external id left behind by a deleted record is pointed at the new record.
+//
+// It returns the record id and whether it was created.
+func (c *RpcClient) UpsertByXMLID(ctx context.Context, model, xmlid string, values map[string]any) (int64, bool, error) {
+	refs, err := c.ResolveXMLIDs(ctx, []string{xmlid})
+	if err != nil {
+		return 0, false, err
+	}
+	ref, ok := refs[xmlid]
+	if !ok {
+		id, err := c.CreateWithXMLID(ctx, model, xmlid, values)
+		return id, err == nil, err
+	}
+	if ref.Model != model {
+		return 0, false, fmt.Errorf("external id %s belongs to %s, not %s", xmlid, ref.Model, model)
+	}
+
+	exists, err := c.Search(ctx, model, NewDomain().In("id", []int64{ref.ID}), Options{Context: map[string]any{"active_test": false}})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(exists) == 0 {
+		id, err := c.Create(ctx, model, values)
+		if err != nil {
+			return 0, false, err
+		}
+		dataIDs, err := c.Search(ctx, "ir.model.data", NewDomain().Equals("module", ref.Module).Equals("name", ref.Name), Options{})
+		if err != nil {
+			return 0, false, err
+		}
+		if _, err := c.Update(ctx, "ir.model.data", dataIDs, map[string]any{"res_id": id}); err != nil {
+			return 0, false, err
+		}
+		return id, true, nil
+	}
+
+	if _, err := c.Update(ctx, model, []int64{ref.ID}, values); err != nil {
+		return 0, false, err
+	}
+	return ref.ID, false, nil
+}
+
+func xmlidFromRecord(rec map[string]any) XMLIDRef {
+	ref := XMLIDRef{}
+	ref.Module, _ = rec["module"].(string)
+	ref.Name, _ = rec["name"].(string)
+	ref.Model, _ = rec["model"].(string)
+	if id, ok := rec["res_id"].(float64); ok {
+		ref.ID = int64(id)
+	}
+	return ref
+}
@@ -0,0 +1,86 @@
+package odoorpc_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Guadalsistema/odoorpc"
+)
+
+func TestResolveXMLIDs(t *testing.T) {
+	first := true
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		if !first {
+			return []any{}, nil
+		}
+		first = false
+		want := []any{
+			"|",
+			"&", []any{"module", "=", "__import__"}, []any{"name", "in", []any{"partner_1", "partner_2"}},
+			"&", []any{"module", "=", "base"}, []any{"name", "in", []any{"main_company"}},
+		}
+		if !reflect.DeepEqual(call.Args[0], want) {
+			t.Errorf("unexpected domain: %#v", call.Args[0])
+		}
+		return []map[string]any{
+			{"module": "__import__", "name": "partner_1", "model": "res.partner", "res_id": 11},
+			{"module": "base", "name": "main_company", "model": "res.company", "res_id": 1},
+		}, nil
+	})
+	c := odoorpc.New(srv.URL, nil)
+	refs, err := c.ResolveXMLIDs(context.Background(), []string{"__import__.partner_1", "base.main_company", "__import__.partner_2"})
+	if err != nil {
+		t.Fatalf("ResolveXMLIDs: %v", err)
+	}
+	if len(refs) != 2 || refs["__import__.partner_1"].ID != 11 || refs["base.main_company"].Model != "res.company" {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+	if _, err := c.ResolveXMLID(context.Background(), "__import__.partner_2"); !errors.Is(err, odoorpc.ErrXMLIDNotFound) {
+		t.Fatalf("expected ErrXMLIDNotFound, got %v", err)
+	}
+	if _, _, err := odoorpc.SplitXMLID("no_module"); err == nil {
+		t.Fatalf("expected invalid external id error")
+	}
+}
+
+func TestUpsertByXMLID(t *testing.T) {
+	var registered map[string]any
+	srv := newFakeOdoo(t, func(call fakeCall) (any, error) {
+		switch {
+		case call.Model == "ir.model.data" && call.Method == "search_read":
+			if registered == nil {
+				return []any{}, nil
+			}
+			return []any{registered}, nil
+		case call.Model == "res.partner" && call.Method == "create":
+			return 42, nil
+		case call.Model == "ir.model.data" && call.Method == "create":
+			registered = call.Args[0].(map[string]any)
+			return 1, nil
+		case call.Model == "res.partner" && call.Method == "search":
+			return []int{42}, nil
+		case call.Model == "res.partner" && call.Method == "write":
+			return true, nil
+		}
+		return nil, &fakeError{Message: "unexpected " + call.Model + "." + call.Method}
+	})
+	c := odoorpc.New(srv.URL, nil)
+	ctx := context.Background()
+
+	id, created, err := c.UpsertByXMLID(ctx, "res.partner", "sync.partner_42", map[string]any{"name": "A"})
+	if err != nil || !created || id != 42 {
+		t.Fatalf("first upsert: id=%d created=%v err=%v", id, created, err)
+	}
+	if registered["module"] != "sync" || registered["name"] != "partner_42" || registered["res_id"] != float64(42) {
+		t.Fatalf("unexpected ir.model.data values: %v", registered)
+	}
+	id, created, err = c.UpsertByXMLID(ctx, "res.partner", "sync.partner_42", map[string]any{"name": "B"})
+	if err != nil || created || id != 42 {
+		t.Fatalf("second upsert: id=%d created=%v err=%v", id, created, err)
+	}
+	if last := srv.Calls[len(srv.Calls)-1]; last.Method != "write" {
+		t.Fatalf("expected write, got %s", last.Method)
+	}
+}